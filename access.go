@@ -0,0 +1,224 @@
+package easylog
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+//AccessFormat selects the line format of an AccessLogger
+type AccessFormat int32
+
+const (
+	//the NCSA common log format:
+	//  host - user [time] "method uri proto" status bytes
+	AccessCommon AccessFormat = iota
+	//the common format followed by "referer" "user-agent"
+	AccessCombined
+	//one JSON object per request, including the latency
+	AccessJSON
+)
+
+//AccessLogger writes one line per HTTP request to the "access" category
+//file, see Category.
+type AccessLogger struct {
+	log        *EasyLog
+	format     AccessFormat
+	trustProxy int32
+}
+
+//return an AccessLogger writing to the "access" category file in format.
+//wrap handlers with Handler, or call Log from the middleware of another
+//framework:
+//
+//  http.ListenAndServe(":8080", access.Handler(mux))
+//  e.Use(echo.WrapMiddleware(access.Handler))
+//  r.Use(func(c *gin.Context) {
+//      start := time.Now()
+//      c.Next()
+//      access.Log(c.Request, c.Writer.Status(), int64(c.Writer.Size()), time.Since(start))
+//  })
+func (t *EasyLog) AccessLog(format AccessFormat) *AccessLogger {
+	child, err := t._category("access")
+	if err != nil {
+		t._reportError(err)
+		child = t
+	}
+
+	return &AccessLogger{log: child, format: format}
+}
+
+//take the client address from the first X-Forwarded-For entry. only enable
+//it behind a proxy that sets the header, clients can forge it.
+func (a *AccessLogger) SetTrustProxy(enable bool) error {
+	var v int32
+	if enable {
+		v = 1
+	}
+
+	atomic.StoreInt32(&a.trustProxy, v)
+
+	return nil
+}
+
+func (a *AccessLogger) GetTrustProxy() bool {
+	return atomic.LoadInt32(&a.trustProxy) == 1
+}
+
+//return next wrapped so that every request it serves is logged
+func (a *AccessLogger) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := a.log._now()
+		rw := &accessWriter{ResponseWriter: w}
+		next.ServeHTTP(rw, r)
+		a.Log(r, rw.status, rw.size, a.log._now().Sub(start))
+	})
+}
+
+//log a served request. status 0 is logged as 200, as net/http sends it.
+func (a *AccessLogger) Log(r *http.Request, status int, size int64, latency time.Duration) {
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	buf := a.log._getBuffer()
+	defer a.log._putBuffer(buf)
+
+	now := a.log._now()
+	ip := a._clientIP(r)
+	uri := r.URL.RequestURI()
+
+	switch a.format {
+	case AccessJSON:
+		buf.WriteString(`{"time":`)
+		_writeJSON(buf, now.Format(time.RFC3339Nano))
+		buf.WriteString(`,"ip":`)
+		_writeJSON(buf, ip)
+		buf.WriteString(`,"method":`)
+		_writeJSON(buf, r.Method)
+		buf.WriteString(`,"path":`)
+		_writeJSON(buf, uri)
+		buf.WriteString(`,"proto":`)
+		_writeJSON(buf, r.Proto)
+		buf.WriteString(`,"status":`)
+		buf.WriteString(strconv.Itoa(status))
+		buf.WriteString(`,"bytes":`)
+		buf.WriteString(strconv.FormatInt(size, 10))
+		buf.WriteString(`,"latency_ms":`)
+		buf.WriteString(strconv.FormatFloat(float64(latency)/float64(time.Millisecond), 'f', 3, 64))
+		buf.WriteString(`,"referer":`)
+		_writeJSON(buf, r.Referer())
+		buf.WriteString(`,"user_agent":`)
+		_writeJSON(buf, r.UserAgent())
+		buf.WriteByte('}')
+	default:
+		user := "-"
+		if name, _, ok := r.BasicAuth(); ok && name != "" {
+			user = name
+		}
+
+		buf.WriteString(ip)
+		buf.WriteString(" - ")
+		buf.WriteString(user)
+		buf.WriteString(" [")
+		_writeTime(buf, now, "02/Jan/2006:15:04:05 -0700")
+		buf.WriteString(`] "`)
+		buf.WriteString(r.Method)
+		buf.WriteByte(' ')
+		buf.WriteString(uri)
+		buf.WriteByte(' ')
+		buf.WriteString(r.Proto)
+		buf.WriteString(`" `)
+		buf.WriteString(strconv.Itoa(status))
+		buf.WriteByte(' ')
+		if size > 0 {
+			buf.WriteString(strconv.FormatInt(size, 10))
+		} else {
+			buf.WriteByte('-')
+		}
+		if a.format == AccessCombined {
+			buf.WriteString(` "`)
+			buf.WriteString(_accessQuote(r.Referer()))
+			buf.WriteString(`" "`)
+			buf.WriteString(_accessQuote(r.UserAgent()))
+			buf.WriteByte('"')
+		}
+	}
+
+	a.log.Write(buf.Bytes())
+}
+
+func (a *AccessLogger) _clientIP(r *http.Request) string {
+	if a.GetTrustProxy() {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			if i := strings.IndexByte(fwd, ','); i >= 0 {
+				fwd = fwd[:i]
+			}
+			return strings.TrimSpace(fwd)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+//header values are quoted in the log line, escape what would end the quote
+func _accessQuote(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+//accessWriter records the status and size of a response
+type accessWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (w *accessWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.size += int64(n)
+
+	return n, err
+}
+
+func (w *accessWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *accessWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+
+	return nil, nil, errors.New("easylog: response writer does not support hijacking")
+}
+
+//for http.ResponseController
+func (w *accessWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
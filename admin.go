@@ -0,0 +1,175 @@
+package easylog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+)
+
+//return an http.Handler for operating the logger at runtime. mount it under
+//a prefix with http.StripPrefix, e.g.
+//
+//  mux.Handle("/debug/log/", http.StripPrefix("/debug/log", log.AdminHandler()))
+//
+//endpoints:
+//
+//  GET  /level                  current level, or a module's with ?module=db
+//  POST /level?level=debug      set the level, or a module's with &module=db
+//  POST /flush                  write pending entries
+//  POST /rotate                 rotate the active file
+//  GET  /stats                  Stats as JSON
+//  GET  /tail?n=100             the last n lines of the active file, default 100
+//  GET  /recent?n=100           the last n entries kept by SetRecentSize
+//
+//the handler has no authentication. only expose it on an internal listener.
+func (t *EasyLog) AdminHandler() http.Handler {
+	return http.HandlerFunc(t._serveAdmin)
+}
+
+func (t *EasyLog) _serveAdmin(w http.ResponseWriter, r *http.Request) {
+	endpoint := path.Base(path.Clean("/" + r.URL.Path))
+	post := r.Method == http.MethodPost || r.Method == http.MethodPut
+
+	switch {
+	case endpoint == "level" && r.Method == http.MethodGet:
+		level := t.GetLevel()
+		if module := r.URL.Query().Get("module"); module != "" {
+			if l, ok := t.GetModuleLevel(module); ok {
+				level = l
+			}
+		}
+		fmt.Fprintln(w, level)
+
+	case endpoint == "level" && post:
+		level, err := ParseLevel(r.FormValue("level"))
+		if err == nil {
+			if module := r.FormValue("module"); module != "" {
+				err = t.SetModuleLevel(module, level)
+			} else {
+				err = t.SetLevel(level)
+			}
+		}
+		_adminReply(w, err)
+
+	case endpoint == "flush" && post:
+		_adminReply(w, t.Flush())
+
+	case endpoint == "rotate" && post:
+		_adminReply(w, t.Rotate())
+
+	case endpoint == "stats" && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(t.Stats())
+
+	case endpoint == "tail" && r.Method == http.MethodGet:
+		n, ok := _adminCount(w, r)
+		if !ok {
+			return
+		}
+
+		t.Flush()
+		cfg := t._fileConfig()
+		lines, err := _tailLines(filepath.Join(cfg.dir, cfg.name), n, t.GetRecordSeparator())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write(lines)
+
+	case endpoint == "recent" && r.Method == http.MethodGet:
+		n, ok := _adminCount(w, r)
+		if !ok {
+			return
+		}
+
+		t.Flush()
+		entries := t.Recent()
+		if len(entries) > n {
+			entries = entries[len(entries)-n:]
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, e := range entries {
+			w.Write(e)
+		}
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+//the n query parameter, 100 if missing
+func _adminCount(w http.ResponseWriter, r *http.Request) (int, bool) {
+	n := 100
+	if v := r.URL.Query().Get("n"); v != "" {
+		var err error
+		if n, err = strconv.Atoi(v); err != nil || n <= 0 {
+			http.Error(w, "easylog: invalid n", http.StatusBadRequest)
+			return 0, false
+		}
+	}
+
+	return n, true
+}
+
+func _adminReply(w http.ResponseWriter, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fmt.Fprintln(w, "ok")
+}
+
+//read the last n lines of the file at path, reading backwards from its end
+func _tailLines(path string, n int, sep string) ([]byte, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	const block = 4096
+	var tail []byte
+	end := []byte(sep)
+	pos := info.Size()
+
+	//n lines need n+1 separators, counting the one ending the last line
+	for pos > 0 && bytes.Count(tail, end) <= n {
+		size := int64(block)
+		if pos < size {
+			size = pos
+		}
+		pos -= size
+
+		chunk := make([]byte, size)
+		if _, err := f.ReadAt(chunk, pos); err != nil && err != io.EOF {
+			return nil, err
+		}
+		tail = append(chunk, tail...)
+	}
+
+	for bytes.Count(tail, end) > n {
+		if cut := bytes.Index(tail, end); cut >= 0 && cut+len(end) < len(tail) {
+			tail = tail[cut+len(end):]
+		} else {
+			break
+		}
+	}
+
+	return tail, nil
+}
@@ -0,0 +1,100 @@
+package easylog
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+type levelCallback struct {
+	min Level
+	fn  func(Entry)
+}
+
+//call fn for every entry at or above level that this logger writes, e.g. to
+//forward errors to Slack, PagerDuty or Sentry. callbacks run one at a time
+//on a background goroutine, so a slow callback never delays logging; entries
+//are dropped and counted in Stats.AlertsDropped when they fall too far
+//behind.
+func (t *EasyLog) OnLevel(level Level, fn func(entry Entry)) error {
+	if !level.valid() {
+		return fmt.Errorf("easylog: invalid level %d", int32(level))
+	}
+	if fn == nil {
+		return nil
+	}
+
+	t.alertMu.Lock()
+	defer t.alertMu.Unlock()
+
+	list := t._levelCallbacks()
+	updated := make([]levelCallback, 0, len(list)+1)
+	updated = append(updated, list...)
+	t.alertList.Store(append(updated, levelCallback{level, fn}))
+
+	return nil
+}
+
+//remove all callbacks added by OnLevel
+func (t *EasyLog) ClearLevelCallbacks() {
+	t.alertMu.Lock()
+	t.alertList.Store([]levelCallback(nil))
+	t.alertMu.Unlock()
+}
+
+func (t *EasyLog) _levelCallbacks() []levelCallback {
+	list, _ := t.alertList.Load().([]levelCallback)
+	return list
+}
+
+//hand an entry to the callback goroutine if any callback wants it
+func (t *EasyLog) _alert(e Entry) {
+	for _, cb := range t._levelCallbacks() {
+		if e.Level < cb.min {
+			continue
+		}
+
+		e.Fields = _copyFields(e.Fields)
+		select {
+		case t.alertCh <- e:
+			t.wakeAlerts()
+		default:
+			atomic.AddUint64(&t.counters.alertDrops, 1)
+		}
+		return
+	}
+}
+
+func (t *EasyLog) _initAlerts() {
+	t.alertCh = make(chan Entry, 256)
+
+	run := func(e Entry) {
+		for _, cb := range t._levelCallbacks() {
+			if e.Level >= cb.min {
+				t._runCallback(cb.fn, e)
+			}
+		}
+	}
+
+	drain := func() {
+		for {
+			select {
+			case e := <-t.alertCh:
+				run(e)
+			default:
+				return
+			}
+		}
+	}
+
+	t.wakeAlerts, _ = t._worker(drain, t.serveDone)
+}
+
+func (t *EasyLog) _runCallback(fn func(Entry), e Entry) {
+	defer func() {
+		if r := recover(); r != nil {
+			t._reportError(fmt.Errorf("easylog: level callback panic: %v", r))
+		}
+	}()
+
+	fn(e)
+}
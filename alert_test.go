@@ -0,0 +1,29 @@
+package easylog
+
+import "testing"
+
+func TestAlertDropsCountedApart(t *testing.T) {
+	l := newTestLog(t, Options{BufferLen: 4096}, realClock{})
+
+	release := make(chan struct{})
+	//runs before the logger is closed, which waits for the callback
+	t.Cleanup(func() { close(release) })
+	l.OnLevel(ErrorLevel, func(Entry) { <-release })
+
+	const n = 1000
+	for i := 0; i < n; i++ {
+		l.Errorf("entry %d", i)
+	}
+	settle(t, l)
+
+	st := l.Stats()
+	if st.AlertsDropped == 0 {
+		t.Error("a blocked callback dropped no entries")
+	}
+	if st.AlertsDropped >= n {
+		t.Errorf("dropped %d of %d entries, the callback queue took none", st.AlertsDropped, n)
+	}
+	if st.Dropped != 0 {
+		t.Errorf("callback drops counted as %d queue drops", st.Dropped)
+	}
+}
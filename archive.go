@@ -0,0 +1,108 @@
+package easylog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+//Archiver uploads rotated files to long-term storage such as S3, GCS or
+//MinIO. easylog doesn't depend on a storage client; wrap the client you
+//already use, e.g. with s3manager.Uploader:
+//
+//  func (a s3Archiver) Upload(name string, body io.Reader, size int64) error {
+//      _, err := a.up.Upload(&s3manager.UploadInput{
+//          Bucket: aws.String(a.bucket), Key: aws.String("logs/" + name), Body: body,
+//      })
+//      return err
+//  }
+type Archiver interface {
+	//store the content of a rotated file. name is its path below the log
+	//directory, with forward slashes, e.g. "app.log.20240517120000.gz".
+	Upload(name string, body io.Reader, size int64) error
+}
+
+const archiveAttempts = 3
+
+//upload every rotated file through a, after compression if it is enabled.
+//failed uploads are retried with backoff and reported through OnError. if
+//removeLocal is true, a file is deleted once uploaded, for services running
+//on ephemeral disks. pass nil to stop uploading.
+func (t *EasyLog) SetArchiver(a Archiver, removeLocal bool) error {
+	t.archiver.Store(archiveConfig{a, removeLocal})
+
+	return nil
+}
+
+type archiveConfig struct {
+	archiver    Archiver
+	removeLocal bool
+}
+
+func (t *EasyLog) _archiveConfig() archiveConfig {
+	c, _ := t.archiver.Load().(archiveConfig)
+	return c
+}
+
+//upload a rotated file, retrying until it succeeds or the logger closes
+func (t *EasyLog) _archive(path string) {
+	c := t._archiveConfig()
+	if c.archiver == nil {
+		return
+	}
+
+	name := _rootRel(t._fileConfig().root, path)
+
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		err := _upload(c.archiver, name, path)
+		if err == nil {
+			break
+		}
+
+		t._reportError(fmt.Errorf("easylog: archive %s (attempt %d): %w", path, attempt, err))
+		if attempt == archiveAttempts {
+			return
+		}
+
+		tm := t.clock.NewTimer(backoff)
+		select {
+		case <-tm.C():
+			backoff *= 2
+		case <-t.quit:
+			//keep the local file, an upload may be slow to give up on
+			tm.Stop()
+			return
+		}
+	}
+
+	if c.removeLocal {
+		t._reportError(os.Remove(path))
+	}
+}
+
+//the path below the log directory, with forward slashes
+func _rootRel(root, path string) string {
+	name, err := filepath.Rel(root, path)
+	if err != nil {
+		name = filepath.Base(path)
+	}
+	return filepath.ToSlash(name)
+}
+
+func _upload(a Archiver, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	return a.Upload(name, f, info.Size())
+}
@@ -0,0 +1,205 @@
+package easylog
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+)
+
+//prefix each entry with a sequence number and a hash chained to the previous
+//entry, as "#<seq> <sha256 hex> <entry>", so Verify can detect modified,
+//removed or reordered entries. on start the chain continues from the last
+//record in the active or newest rotated file. the chain covers a single
+//process and plain files; encrypted files restart it.
+func (t *EasyLog) SetAuditMode(enable bool) error {
+	var v int32
+	if enable {
+		v = 1
+	}
+
+	atomic.StoreInt32(&t.audit, v)
+
+	return nil
+}
+
+func (t *EasyLog) GetAuditMode() bool {
+	return atomic.LoadInt32(&t.audit) == 1
+}
+
+func _auditHash(prev []byte, seq uint64, body []byte) []byte {
+	h := sha256.New()
+	h.Write(prev)
+	h.Write([]byte(strconv.FormatUint(seq, 10)))
+	h.Write([]byte{' '})
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+//write entry to data as the next audit record
+func (t *EasyLog) _auditRecord(data *bytes.Buffer, entry []byte) {
+	if !t.auditInit {
+		t.auditInit = true
+		t.auditSeq, t.auditPrev = t._auditResume()
+	}
+
+	sep := t.GetRecordSeparator()
+	body := bytes.TrimSuffix(entry, []byte(sep))
+	t.auditSeq++
+	t.auditPrev = _auditHash(t.auditPrev, t.auditSeq, body)
+
+	data.WriteByte('#')
+	data.WriteString(strconv.FormatUint(t.auditSeq, 10))
+	data.WriteByte(' ')
+	data.WriteString(hex.EncodeToString(t.auditPrev))
+	data.WriteByte(' ')
+	data.Write(body)
+	data.WriteString(sep)
+}
+
+//find the last record written by an earlier run
+func (t *EasyLog) _auditResume() (uint64, []byte) {
+	cfg := t._fileConfig()
+
+	r := Open(cfg.dir, cfg.name).SetRotateNamePattern(cfg.pattern).SetRecordSeparator(t.GetRecordSeparator())
+	if r._listFiles() != nil {
+		return 0, nil
+	}
+	files := r.files
+
+	for i := len(files) - 1; i >= 0; i-- {
+		r.files = files[i : i+1]
+		if r._openNext() != nil {
+			continue
+		}
+
+		var seq uint64
+		var hash []byte
+		for r.scan.Scan() {
+			if s, h, _, ok := _parseAuditHeader(r.scan.Bytes()); ok {
+				seq, hash = s, h
+			}
+		}
+		r._closeFile()
+
+		if hash != nil {
+			return seq, hash
+		}
+	}
+
+	return 0, nil
+}
+
+//split "#<seq> <hash> <body>"
+func _parseAuditHeader(line []byte) (seq uint64, hash []byte, body []byte, ok bool) {
+	if len(line) == 0 || line[0] != '#' {
+		return 0, nil, nil, false
+	}
+
+	sp := bytes.IndexByte(line, ' ')
+	if sp < 2 || len(line) < sp+1+sha256.Size*2+1 || line[sp+1+sha256.Size*2] != ' ' {
+		return 0, nil, nil, false
+	}
+
+	seq, err := strconv.ParseUint(string(line[1:sp]), 10, 64)
+	if err != nil {
+		return 0, nil, nil, false
+	}
+
+	hash, err = hex.DecodeString(string(line[sp+1 : sp+1+sha256.Size*2]))
+	if err != nil {
+		return 0, nil, nil, false
+	}
+
+	return seq, hash, line[sp+1+sha256.Size*2+1:], true
+}
+
+//check the audit chain of the files written to dir under name. it returns the
+//number of records checked and an error describing the first modified,
+//missing or reordered record. the chain may start after rotated files were
+//deleted by retention, but must be continuous from there on. files written
+//with SetRecordSeparator are verified with Reader.SetRecordSeparator.
+func Verify(dir, name string) (uint64, error) {
+	return Open(dir, name).Verify()
+}
+
+//check the audit chain of the reader's files, see Verify
+func (r *Reader) Verify() (uint64, error) {
+	if err := r._listFiles(); err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	var count, seq uint64
+	var prev []byte
+	var rec bytes.Buffer
+	var recSeq uint64
+	var recHash []byte
+	var recFile string
+	started, pending := false, false
+	sep := r.separator
+	if sep == "" {
+		sep = "\n"
+	}
+
+	check := func() error {
+		if !pending {
+			return nil
+		}
+		pending = false
+		count++
+
+		if started && recSeq != seq+1 {
+			return fmt.Errorf("easylog: audit record %d in %s: expected record %d", recSeq, recFile, seq+1)
+		}
+		//the first record of a chain can be checked, later ones need prev
+		if (started || recSeq == 1) && !bytes.Equal(_auditHash(prev, recSeq, rec.Bytes()), recHash) {
+			return fmt.Errorf("easylog: audit record %d in %s: hash mismatch", recSeq, recFile)
+		}
+
+		started = true
+		seq, prev = recSeq, recHash
+
+		return nil
+	}
+
+	for len(r.files) > 0 {
+		if err := r._openNext(); err != nil {
+			return count, err
+		}
+		file := filepath.Base(r.rec.File)
+
+		for r.scan.Scan() {
+			line := r.scan.Bytes()
+			if s, h, body, ok := _parseAuditHeader(line); ok {
+				if err := check(); err != nil {
+					return count, err
+				}
+				recSeq, recHash, recFile, pending = s, h, file, true
+				rec.Reset()
+				rec.Write(body)
+				continue
+			}
+
+			//lines before the first record were written before audit mode
+			//was enabled. later ones continue an entry holding the separator,
+			//e.g. a multi-line one.
+			if !pending {
+				continue
+			}
+			rec.WriteString(sep)
+			rec.Write(line)
+		}
+
+		err := r.scan.Err()
+		r._closeFile()
+		if err != nil {
+			return count, err
+		}
+	}
+
+	return count, check()
+}
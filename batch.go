@@ -0,0 +1,137 @@
+package easylog
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+const defaultBatchSize = 1024 * 1024
+
+//set how many bytes are collected into one batch. a full batch is written
+//immediately, without waiting for the flush tick, and a batch is never larger
+//than the max file size.
+//larger batches mean fewer write calls. if BatchSize <= 0, the default of
+//1MB is used.
+func (t *EasyLog) SetBatchSize(BatchSize int64) error {
+	if BatchSize <= 0 {
+		BatchSize = defaultBatchSize
+	}
+
+	atomic.StoreInt64(&t.batchSize, BatchSize)
+
+	return nil
+}
+
+func (t *EasyLog) GetBatchSize() int64 {
+	return atomic.LoadInt64(&t.batchSize)
+}
+
+//set the longest time an entry waits in a batch before it is written, even
+//if the flush interval is longer. if MaxBatchDelay == 0, batches are only
+//written on the flush interval or when they are full.
+func (t *EasyLog) SetMaxBatchDelay(MaxBatchDelay time.Duration) error {
+	if MaxBatchDelay < 0 {
+		MaxBatchDelay = 0
+	}
+
+	atomic.StoreInt64(&t.maxBatchDelay, int64(MaxBatchDelay))
+
+	return nil
+}
+
+func (t *EasyLog) GetMaxBatchDelay() time.Duration {
+	return time.Duration(atomic.LoadInt64(&t.maxBatchDelay))
+}
+
+//write a batch once it holds n entries, however small they are and however
+//long the flush interval, which bounds the latency of loggers written to at
+//a moderate rate without a fast ticker. if n == 0, batches are only written
+//on the flush interval, when they are full or after MaxBatchDelay.
+func (t *EasyLog) SetFlushEvery(n int) error {
+	if n < 0 {
+		n = 0
+	}
+
+	atomic.StoreInt64(&t.flushEvery, int64(n))
+
+	return nil
+}
+
+func (t *EasyLog) GetFlushEvery() int {
+	return int(atomic.LoadInt64(&t.flushEvery))
+}
+
+//bytes to collect before writing a batch
+func (t *EasyLog) _batchLimit() int {
+	n := t.GetBatchSize()
+	if max := t.GetMaxFileSize(); n > max {
+		n = max
+	}
+
+	return int(n)
+}
+
+//the log file held open by the serve goroutine. it is reopened when the
+//configured path changes, closed around rotation and after a failed write.
+//its size is tracked in fileSize so writes don't need a stat call.
+func (t *EasyLog) _openFile(cfg fileConfig) (*os.File, error) {
+	fullPath := filepath.Join(cfg.dir, cfg.name)
+	if t.file != nil && t.filePath == fullPath {
+		return t.file, nil
+	}
+
+	//the file at the earlier path is done with
+	if t.file != nil {
+		t._footer(t.file)
+	}
+	t._closeFile()
+
+	if err := _makeDir(cfg); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(fullPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, cfg.fileMode)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	t.file = f
+	t.filePath = fullPath
+	t.fileSize = info.Size()
+
+	return f, nil
+}
+
+func (t *EasyLog) _closeFile() error {
+	if t.file == nil {
+		return nil
+	}
+
+	err := t.file.Close()
+	t.file = nil
+	t.filePath = ""
+	t.fileSize = 0
+	t.headerSize = 0
+
+	return err
+}
+
+//write data to the open file. on error the file is closed, so the next
+//write opens the path again instead of reusing a broken handle.
+func (t *EasyLog) _writeOpen(f *os.File, data []byte) error {
+	n, err := f.Write(data)
+	t.fileSize += int64(n)
+	if err != nil {
+		t._closeFile()
+	}
+
+	return err
+}
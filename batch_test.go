@@ -0,0 +1,97 @@
+package easylog
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFullBatchBeforeTick(t *testing.T) {
+	c := newFakeClock(time.Now())
+	l := newTestLog(t, Options{FlushFreq: time.Hour, BatchSize: 4096}, c)
+
+	entry := strings.Repeat("x", 1023)
+	for i := 0; i < 3; i++ {
+		l.WriteString(entry)
+	}
+	settle(t, l)
+	if got := readLog(t, l); got != "" {
+		t.Fatalf("flushed %d bytes before the batch was full", len(got))
+	}
+
+	//the fourth entry fills the batch, the clock never ticked
+	l.WriteString(entry)
+	settle(t, l)
+	if got := readLog(t, l); len(got) != 4096 {
+		t.Fatalf("the file holds %d bytes, want the full batch of 4096", len(got))
+	}
+}
+
+func TestBurstBeforeTick(t *testing.T) {
+	c := newFakeClock(time.Now())
+	l := newTestLog(t, Options{FlushFreq: time.Hour, BatchSize: 16 * 1024}, c)
+
+	const writers, entries = 8, 500
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < entries; i++ {
+				fmt.Fprintf(l, "writer %d entry %04d %s", w, i, strings.Repeat("y", 64))
+			}
+		}(w)
+	}
+	wg.Wait()
+	settle(t, l)
+
+	//all but the last partial batch is on disk
+	got := readLog(t, l)
+	total := writers * entries * (len("writer 0 entry 0000 ") + 64 + 1)
+	if len(got) <= total-16*1024 {
+		t.Fatalf("the file holds %d of %d bytes before any tick", len(got), total)
+	}
+	if !strings.HasSuffix(got, "\n") {
+		t.Fatal("a batch was cut inside an entry")
+	}
+
+	//each writer's entries stay in order
+	next := make([]int, writers)
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\n"), "\n") {
+		var w, i int
+		if _, err := fmt.Sscanf(line, "writer %d entry %d", &w, &i); err != nil {
+			t.Fatalf("bad line %q: %v", line, err)
+		}
+		if i != next[w] {
+			t.Fatalf("writer %d: entry %d after %d", w, i, next[w]-1)
+		}
+		next[w]++
+	}
+
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readLog(t, l); len(got) != total {
+		t.Fatalf("after Flush the file holds %d of %d bytes", len(got), total)
+	}
+}
+
+func TestFlushEvery(t *testing.T) {
+	c := newFakeClock(time.Now())
+	l := newTestLog(t, Options{FlushFreq: time.Hour, FlushEvery: 3}, c)
+
+	l.Write([]byte("one"))
+	l.Write([]byte("two"))
+	settle(t, l)
+	if got := readLog(t, l); got != "" {
+		t.Fatalf("flushed before the third entry: %q", got)
+	}
+
+	l.Write([]byte("three"))
+	settle(t, l)
+	if got := readLog(t, l); got != "one\ntwo\nthree\n" {
+		t.Fatalf("the file holds %q", got)
+	}
+}
@@ -0,0 +1,146 @@
+package easylog
+
+import (
+	"testing"
+	"time"
+)
+
+//the benchmarks of the common logging paths. they write to a temporary
+//directory, or nowhere for the Discard variants, which measure the pipeline
+//apart from disk I/O:
+//
+//  go test -run '^$' -bench . -benchmem
+
+var benchLine = []byte("2024-05-17 10:00:00.000 [INFO] request served in 12ms\n")
+
+func newBenchLog(b *testing.B, opts Options, discard bool) *EasyLog {
+	if opts.MaxFileSize == 0 {
+		opts.MaxFileSize = 64 * 1024 * 1024
+	}
+	l := newTestLog(b, opts, realClock{})
+	if discard {
+		l.SetSink(Discard)
+	}
+
+	return l
+}
+
+func BenchmarkWrite(b *testing.B) {
+	l := newBenchLog(b, Options{}, false)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Write(benchLine)
+	}
+	l.Flush()
+}
+
+func BenchmarkWriteDiscard(b *testing.B) {
+	l := newBenchLog(b, Options{}, true)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Write(benchLine)
+	}
+	l.Flush()
+}
+
+func BenchmarkWriteParallel(b *testing.B) {
+	l := newBenchLog(b, Options{}, false)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Write(benchLine)
+		}
+	})
+	l.Flush()
+}
+
+func BenchmarkInfofPlain(b *testing.B) {
+	l := newBenchLog(b, Options{}, false)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Infof("request served in %dms", 12)
+	}
+	l.Flush()
+}
+
+func BenchmarkInfofFields(b *testing.B) {
+	l := newBenchLog(b, Options{}, false)
+	logger := l.With("request_id", "8f14e45f", "status", 200)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Infof("request served in %dms", 12)
+	}
+	l.Flush()
+}
+
+func BenchmarkInfofJSON(b *testing.B) {
+	l := newBenchLog(b, Options{Format: JSONFormat}, false)
+	logger := l.With("request_id", "8f14e45f", "status", 200)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Infof("request served in %dms", 12)
+	}
+	l.Flush()
+}
+
+//Write takes its buffer from the pool and the serve goroutine returns it, so
+//once the pool is warm a Write allocates nothing, on either side
+func TestWriteDoesNotAllocate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("measures allocations")
+	}
+
+	l := newTestLog(t, Options{FlushFreq: time.Hour}, realClock{})
+	l.SetSink(Discard)
+	for i := 0; i < 1000; i++ {
+		l.Write(benchLine)
+	}
+	l.Flush()
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		l.Write(benchLine)
+	})
+	if allocs >= 1 {
+		t.Fatalf("Write allocates %.2f times per call", allocs)
+	}
+}
+
+//throughput by batch size: larger batches mean fewer write calls. the
+//SyncEveryFlush variants show the fsync each batch costs.
+func BenchmarkBatchSize(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		size int64
+		sync SyncPolicy
+	}{
+		{"4KB", 4 * 1024, SyncNever},
+		{"64KB", 64 * 1024, SyncNever},
+		{"1MB", 1024 * 1024, SyncNever},
+		{"4KB-fsync", 4 * 1024, SyncEveryFlush},
+		{"1MB-fsync", 1024 * 1024, SyncEveryFlush},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			l := newBenchLog(b, Options{BatchSize: bc.size, SyncPolicy: bc.sync}, false)
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(benchLine)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				l.Write(benchLine)
+			}
+			l.Flush()
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package easylog
+
+import (
+	"path"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+//frames between the public logging method and _withCaller
+const callerDepth = 3
+
+//add the caller's file:line as the "caller" field and its function as the
+//"func" field of every leveled entry
+func (t *EasyLog) SetReportCaller(enable bool) error {
+	var v int32
+	if enable {
+		v = 1
+	}
+
+	atomic.StoreInt32(&t.reportCaller, v)
+
+	return nil
+}
+
+func (t *EasyLog) GetReportCaller() bool {
+	return atomic.LoadInt32(&t.reportCaller) == 1
+}
+
+//set how many extra stack frames to skip when reporting the caller. wrappers
+//around EasyLog's methods set it to their own depth so the true call site is
+//reported.
+func (t *EasyLog) SetCallerSkip(skip int) error {
+	if skip < 0 {
+		skip = 0
+	}
+
+	atomic.StoreInt32(&t.callerSkip, int32(skip))
+
+	return nil
+}
+
+//return fields plus the caller and a stack trace of an entry at level, if
+//enabled. fields is not modified.
+func (t *EasyLog) _withCaller(level Level, fields Fields) Fields {
+	stack := t._wantStack(level)
+	if !t.GetReportCaller() && !stack {
+		return fields
+	}
+
+	skip := callerDepth + int(atomic.LoadInt32(&t.callerSkip))
+	withCaller := _copyFields(fields)
+
+	if t.GetReportCaller() {
+		if pc, file, line, ok := runtime.Caller(skip); ok {
+			frame := runtime.Frame{PC: pc, File: file, Line: line}
+			if fn := runtime.FuncForPC(pc); fn != nil {
+				frame.Function = fn.Name()
+			}
+			_addCaller(withCaller, frame)
+		}
+	}
+
+	if stack {
+		withCaller["stack"] = _stacktrace(skip, t.GetStacktraceDepth())
+	}
+
+	return withCaller
+}
+
+func _addCaller(fields Fields, frame runtime.Frame) {
+	//dir/file.go is enough to find the source and keeps lines short.
+	//runtime reports paths with forward slashes on every platform.
+	file := path.Join(path.Base(path.Dir(frame.File)), path.Base(frame.File))
+
+	fields["caller"] = file + ":" + strconv.Itoa(frame.Line)
+	if frame.Function != "" {
+		fields["func"] = frame.Function
+	}
+}
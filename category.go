@@ -0,0 +1,164 @@
+package easylog
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+//return a Logger writing to its own file next to the log file, named after
+//category with the log file's extension, e.g. "access" gives access.log
+//beside app.log. web servers use it to keep access logs apart from
+//application logs. the category file takes the logger's rotation, retention,
+//format, level, global fields, redactors and flush settings, such as
+//encryption, when first used, and is closed with it.
+//if the category file can't be opened, the error is reported to the
+//OnError func and entries go to the log file instead.
+//
+//  log.Category("access").Infof("%s %s %d", r.Method, r.URL, status)
+func (t *EasyLog) Category(category string) *Logger {
+	child, err := t._category(category)
+	if err != nil {
+		t._reportError(err)
+		return &Logger{log: t}
+	}
+
+	return &Logger{log: child}
+}
+
+func (t *EasyLog) _category(category string) (*EasyLog, error) {
+	if child := t._categories()[category]; child != nil {
+		return child, nil
+	}
+
+	if category == "" || strings.ContainsAny(category, `/\`) {
+		return nil, fmt.Errorf("easylog: invalid category %q", category)
+	}
+
+	t.categoryMu.Lock()
+	defer t.categoryMu.Unlock()
+
+	t.closeMu.RLock()
+	closed := t.closed
+	t.closeMu.RUnlock()
+	if closed {
+		return nil, ErrClosed
+	}
+
+	list := t._categories()
+	if child := list[category]; child != nil {
+		return child, nil
+	}
+
+	cfg := t._fileConfig()
+	ext := filepath.Ext(cfg.name)
+	if ext == "" {
+		ext = ".log"
+	}
+	name := category + ext
+	if name == cfg.name {
+		return nil, fmt.Errorf("easylog: category %q would write to the log file", category)
+	}
+
+	child, err := t._newChild(cfg.root, name)
+	if err != nil {
+		return nil, err
+	}
+
+	next := make(map[string]*EasyLog, len(list)+1)
+	for k, v := range list {
+		next[k] = v
+	}
+	next[category] = child
+	t.categories.Store(next)
+
+	return child, nil
+}
+
+//create a logger writing to name in dir, with t's rotation, retention,
+//format, level, global fields and redactors, and the settings its batches
+//are flushed with
+func (t *EasyLog) _newChild(dir, name string) (*EasyLog, error) {
+	cfg := t._fileConfig()
+	dirMode, fileMode := t.GetPermissions()
+	enc := t._encodeConfig()
+	header, _ := t.fileHeader.Load().(frameFunc)
+	footer, _ := t.fileFooter.Load().(frameFunc)
+	child, err := _newLogWithClock(Options{
+		Dir:               dir,
+		FileName:          name,
+		FallbackDir:       cfg.fallbackDir,
+		DailyDirs:         cfg.daily,
+		LazyCreate:        cfg.lazy,
+		DirMode:           dirMode,
+		FileMode:          fileMode,
+		MaxFileSize:       cfg.maxSize,
+		MaxFileCount:      cfg.maxCount,
+		MaxFileAge:        t.GetMaxFileAge(),
+		MaxTotalSize:      t.GetMaxTotalSize(),
+		RotateInterval:    t.GetRotateInterval(),
+		RotateNamePattern: cfg.pattern,
+		CompressRotated:   t.GetCompressRotated(),
+		CompressionCodec:  t.GetCompressionCodec(),
+		Manifest:          t.GetManifest(),
+		BufferLen:         cap(t.pipe),
+		FlushFreq:         t.GetFlushFreq(),
+		MaxBufferCap:      atomic.LoadInt64(&t.maxBufferCap),
+		NoBufferPool:      !t.GetBufferPooling(),
+		BatchSize:         t.GetBatchSize(),
+		MaxBatchDelay:     t.GetMaxBatchDelay(),
+		FlushEvery:        t.GetFlushEvery(),
+		Level:             t.GetLevel(),
+		Format:            t.GetFormat(),
+		QueuePolicy:       t.GetQueuePolicy(),
+		SyncPolicy:        t.GetSyncPolicy(),
+		WriteTimeout:      t.GetWriteTimeout(),
+		TimeFormat:        enc.timeFormat,
+		Prefix:            enc.prefix,
+		CSVColumns:        t.GetCSVColumns(),
+		EscapeMode:        t.GetEscapeMode(),
+		GlobalFields:      t._globalFields(),
+		HostFields:        t.GetHostFields(),
+		RecordSeparator:   t.GetRecordSeparator(),
+		FileHeader:        header,
+		FileFooter:        footer,
+		Encoder:           t.GetEncoder(),
+		Encoding:          t.GetEncoding(),
+		Scheduler:         t.sched,
+	}, t.clock)
+	if err != nil {
+		return nil, err
+	}
+	child.redactList.Store(t._redactors())
+
+	//settings Options doesn't carry
+	box, _ := t.crypt.Load().(aeadBox)
+	child.crypt.Store(box)
+	child.archiver.Store(t._archiveConfig())
+	child.SetAuditMode(t.GetAuditMode())
+	child.SetSynchronous(t.GetSynchronous())
+	child.SetRetryBuffer(t.GetRetryBuffer())
+	if t.GetMultiProcess() {
+		child.SetMultiProcess(true)
+	}
+	child.OnError(t._reportError)
+
+	var discard bool
+	t._do(func(data *bytes.Buffer) error {
+		_, discard = t.sink.(*discarder)
+		return nil
+	})
+	if discard {
+		child.SetSink(Discard)
+	}
+
+	return child, nil
+}
+
+//the category files opened so far
+func (t *EasyLog) _categories() map[string]*EasyLog {
+	list, _ := t.categories.Load().(map[string]*EasyLog)
+	return list
+}
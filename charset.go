@@ -0,0 +1,135 @@
+package easylog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+//Transcoder converts UTF-8 entries to another charset, see SetEncoding.
+//*encoding.Encoder of golang.org/x/text implements it, so any of its
+//charsets can be used without easylog depending on it:
+//
+//  log.SetEncoding(simplifiedchinese.GBK.NewEncoder())
+//
+//x/text encoders fail on characters the charset can't represent; wrap them
+//with encoding.ReplaceUnsupported to write a replacement instead.
+type Transcoder interface {
+	Bytes(b []byte) ([]byte, error)
+}
+
+type transcoderBox struct {
+	tc Transcoder
+}
+
+//convert the entries written to the log file or sink from UTF-8 with tc,
+//for consumers that require e.g. GBK or UTF-16. outputs, Recent and the
+//console keep UTF-8; wrap an output with TranscodeWriter to convert it too.
+//entries tc fails on are written unconverted and the error is reported.
+//with a rotating file, the max file size is checked before conversion, so a
+//charset wider than UTF-8 may exceed it by up to one batch.
+//if tc == nil, entries are written as UTF-8. this is the default.
+func (t *EasyLog) SetEncoding(tc Transcoder) error {
+	t.charset.Store(transcoderBox{tc})
+	return nil
+}
+
+func (t *EasyLog) GetEncoding() Transcoder {
+	box, _ := t.charset.Load().(transcoderBox)
+	return box.tc
+}
+
+//return a writer converting everything written to it with tc before
+//writing it to w, e.g. for AddOutput. the log stream is written in whole
+//entries, so no character is split between writes.
+func TranscodeWriter(w io.Writer, tc Transcoder) io.Writer {
+	return transcodeWriter{w, tc}
+}
+
+type transcodeWriter struct {
+	w  io.Writer
+	tc Transcoder
+}
+
+func (w transcodeWriter) Write(p []byte) (int, error) {
+	out, err := w.tc.Bytes(p)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := w.w.Write(out); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+//return a Transcoder to UTF-16, little endian unless bigEndian is set. no
+//byte order mark is written; start the file header with "\ufeff" to add one.
+//invalid UTF-8 is replaced by U+FFFD.
+func UTF16(bigEndian bool) Transcoder {
+	return utf16Transcoder{bigEndian}
+}
+
+type utf16Transcoder struct {
+	bigEndian bool
+}
+
+func (c utf16Transcoder) Bytes(b []byte) ([]byte, error) {
+	out := make([]byte, 0, len(b)*2)
+	put := func(u uint16) {
+		if c.bigEndian {
+			out = append(out, byte(u>>8), byte(u))
+		} else {
+			out = append(out, byte(u), byte(u>>8))
+		}
+	}
+
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		b = b[size:]
+		if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+			put(uint16(r1))
+			put(uint16(r2))
+			continue
+		}
+		put(uint16(r))
+	}
+
+	return out, nil
+}
+
+//entries are converted before encryption, which they are never split
+//across files under, and before a custom sink. only called from the serve
+//goroutine.
+func (t *EasyLog) _transcodeEarly() bool {
+	box, _ := t.crypt.Load().(aeadBox)
+	return box.aead != nil || t.sink != nil
+}
+
+//convert p with the configured Transcoder, if any
+func (t *EasyLog) _transcode(p []byte) []byte {
+	tc := t.GetEncoding()
+	if tc == nil || len(p) == 0 {
+		return p
+	}
+
+	out, err := tc.Bytes(p)
+	if err != nil {
+		t._reportError(fmt.Errorf("easylog: convert entries: %w", err))
+		return p
+	}
+
+	return out
+}
+
+func (t *EasyLog) _transcodeBuffer(buf *bytes.Buffer) {
+	if t.GetEncoding() == nil {
+		return
+	}
+
+	out := t._transcode(buf.Bytes())
+	buf.Reset()
+	buf.Write(out)
+}
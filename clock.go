@@ -0,0 +1,66 @@
+package easylog
+
+import "time"
+
+//clock is the source of time for timestamps, flush ticks, batch delays,
+//rotation, retention and retries. tests replace it, before _start, to control
+//time without sleeping, see _newLogWithClock.
+type clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) ticker
+	NewTimer(d time.Duration) timer
+	//run fn on its own goroutine once d elapsed
+	AfterFunc(d time.Duration, fn func()) timer
+}
+
+type ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+//like time.Timer. the C of an AfterFunc timer is nil.
+type timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+//the wall clock
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTicker(d time.Duration) ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+func (realClock) NewTimer(d time.Duration) timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, fn func()) timer {
+	return realTimer{time.AfterFunc(d, fn)}
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+//the current time of t's clock
+func (t *EasyLog) _now() time.Time {
+	return t.clock.Now()
+}
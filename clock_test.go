@@ -0,0 +1,157 @@
+package easylog
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+//fakeClock only moves when a test calls Advance
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+//a timer, ticker or AfterFunc of a fakeClock
+type fakeTimer struct {
+	c       *fakeClock
+	ch      chan time.Time
+	fn      func()
+	when    time.Time
+	period  time.Duration
+	active  bool
+	stopped bool
+}
+
+type fakeTicker struct {
+	*fakeTimer
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) ticker {
+	return fakeTicker{c._add(d, d, nil)}
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) timer {
+	return c._add(d, 0, nil)
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, fn func()) timer {
+	return c._add(d, 0, fn)
+}
+
+func (c *fakeClock) _add(d, period time.Duration, fn func()) *fakeTimer {
+	tm := &fakeTimer{c: c, fn: fn, period: period}
+	if fn == nil {
+		tm.ch = make(chan time.Time, 1)
+	}
+
+	c.mu.Lock()
+	tm.when = c.now.Add(d)
+	tm.active = true
+	c.timers = append(c.timers, tm)
+	c.mu.Unlock()
+
+	return tm
+}
+
+//move the time forward by d, firing the timers due on the way in order.
+//a fired channel is taken by its reader before time moves on, so once
+//Advance returns, the reader is handling the tick.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		var next *fakeTimer
+		for _, tm := range c.timers {
+			if tm.active && !tm.when.After(end) && (next == nil || tm.when.Before(next.when)) {
+				next = tm
+			}
+		}
+		if next == nil {
+			c.now = end
+			c.mu.Unlock()
+			return
+		}
+
+		c.now = next.when
+		if next.period > 0 {
+			next.when = next.when.Add(next.period)
+		} else {
+			next.active = false
+		}
+		now := c.now
+		c.mu.Unlock()
+
+		if next.fn != nil {
+			next.fn()
+			continue
+		}
+
+		select {
+		case next.ch <- now:
+		default:
+		}
+		next._taken()
+	}
+}
+
+//wait until the reader took the fired value, or stopped the timer
+func (tm *fakeTimer) _taken() {
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		tm.c.mu.Lock()
+		done := len(tm.ch) == 0 || tm.stopped
+		tm.c.mu.Unlock()
+		if done {
+			return
+		}
+		if time.Now().After(deadline) {
+			panic("fakeClock: fired timer is never read")
+		}
+		runtime.Gosched()
+	}
+}
+
+func (tm *fakeTimer) C() <-chan time.Time {
+	return tm.ch
+}
+
+func (tm *fakeTimer) Stop() bool {
+	tm.c.mu.Lock()
+	defer tm.c.mu.Unlock()
+
+	active := tm.active
+	tm.active, tm.stopped = false, true
+
+	return active
+}
+
+func (tm *fakeTimer) Reset(d time.Duration) bool {
+	tm.c.mu.Lock()
+	defer tm.c.mu.Unlock()
+
+	active := tm.active
+	tm.when = tm.c.now.Add(d)
+	tm.active, tm.stopped = true, false
+
+	return active
+}
+
+func (tk fakeTicker) Stop() {
+	tk.fakeTimer.Stop()
+}
@@ -0,0 +1,97 @@
+//easylog-bench measures the throughput and allocations of the common
+//logging paths. entries go to a temporary directory that is removed
+//afterwards.
+//
+//  easylog-bench
+//  easylog-bench -format json
+//  easylog-bench -discard
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/carr123/easylog"
+)
+
+func main() {
+	format := flag.String("format", "text", "entry format: text, json, logfmt or csv")
+	discard := flag.Bool("discard", false, "write nothing, to measure the pipeline without disk I/O")
+	flag.Parse()
+
+	dir, err := ioutil.TempDir("", "easylog-bench")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "easylog-bench:", err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(dir)
+
+	opts := easylog.Options{Dir: dir, FileName: "bench.log", MaxFileSize: 64 * 1024 * 1024}
+	switch *format {
+	case "json":
+		opts.Format = easylog.JSONFormat
+	case "logfmt":
+		opts.Format = easylog.LogfmtFormat
+	case "csv":
+		opts.Format = easylog.CSVFormat
+	}
+
+	l, err := easylog.NewLogWithOptions(opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "easylog-bench:", err)
+		os.Exit(1)
+	}
+	defer l.Close()
+
+	if *discard {
+		l.SetSink(easylog.Discard)
+	}
+
+	line := []byte("2024-05-17 10:00:00.000 [INFO] request served in 12ms\n")
+	logger := l.With("request_id", "8f14e45f", "status", 200)
+
+	run("Write", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			l.Write(line)
+		}
+	})
+	run("WriteString", func(b *testing.B) {
+		s := string(line)
+		for i := 0; i < b.N; i++ {
+			l.WriteString(s)
+		}
+	})
+	run("Print", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			l.Print("request served")
+		}
+	})
+	run("Infof", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			l.Infof("request served in %dms", 12)
+		}
+	})
+	run("Logger.Print", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			logger.Print("request served")
+		}
+	})
+	run("Debugf disabled", func(b *testing.B) {
+		l.SetLevel(easylog.InfoLevel)
+		defer l.SetLevel(easylog.DebugLevel)
+		for i := 0; i < b.N; i++ {
+			l.Debugf("request served in %dms", 12)
+		}
+	})
+}
+
+func run(name string, fn func(b *testing.B)) {
+	r := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		fn(b)
+	})
+	fmt.Printf("%-16s %s %s\n", name, r.String(), r.MemString())
+}
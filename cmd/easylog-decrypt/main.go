@@ -0,0 +1,69 @@
+//easylog-decrypt prints the plain text of log files encrypted by
+//EasyLog.SetEncryptionKey. rotated files compressed to .gz are
+//decompressed first.
+//
+//  easylog-decrypt -key <hex> app.log app.log.20240517000000.gz
+//  EASYLOG_KEY=<hex> easylog-decrypt app.log
+package main
+
+import (
+	"compress/gzip"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/carr123/easylog"
+)
+
+func main() {
+	keyHex := flag.String("key", os.Getenv("EASYLOG_KEY"), "hex encoded AES key, defaults to $EASYLOG_KEY")
+	flag.Parse()
+
+	key, err := hex.DecodeString(strings.TrimSpace(*keyHex))
+	if err != nil || len(key) == 0 {
+		fmt.Fprintln(os.Stderr, "easylog-decrypt: a hex encoded -key is required")
+		os.Exit(2)
+	}
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: easylog-decrypt -key <hex> file...")
+		os.Exit(2)
+	}
+
+	for _, path := range flag.Args() {
+		if err := decryptFile(path, key, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "easylog-decrypt: %s: %v\n", path, err)
+			os.Exit(1)
+		}
+	}
+}
+
+func decryptFile(path string, key []byte, w io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	dr, err := easylog.NewDecryptReader(r, key)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, dr)
+
+	return err
+}
@@ -0,0 +1,70 @@
+package easylog
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+//CompressionCodec compresses rotated files, see SetCompressionCodec. gzip is
+//built in, other formats are a few lines on top of their packages, e.g. for
+//zstd with github.com/klauspost/compress/zstd:
+//
+//  type zstdCodec struct{}
+//
+//  func (zstdCodec) Extension() string { return ".zst" }
+//  func (zstdCodec) NewWriter(w io.Writer) (io.WriteCloser, error) { return zstd.NewWriter(w) }
+//  func (zstdCodec) NewReader(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) }
+//
+//and likewise for lz4 with github.com/pierrec/lz4.
+type CompressionCodec interface {
+	//the suffix added to compressed files, e.g. ".gz"
+	Extension() string
+	//compress to w. Close must flush the compressed data, but not close w.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	//decompress r, used by Reader
+	NewReader(r io.Reader) (io.Reader, error)
+}
+
+type codecBox struct {
+	codec CompressionCodec
+}
+
+type gzipCodec struct {
+	level int
+}
+
+//return the gzip codec at a compression level of compress/gzip, from
+//gzip.BestSpeed to gzip.BestCompression. it is the default, with
+//gzip.DefaultCompression.
+func GzipCodec(level int) CompressionCodec {
+	return gzipCodec{level: level}
+}
+
+func (gzipCodec) Extension() string {
+	return ".gz"
+}
+
+func (c gzipCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(w, c.level)
+}
+
+func (gzipCodec) NewReader(r io.Reader) (io.Reader, error) {
+	return gzip.NewReader(r)
+}
+
+//set the codec SetCompressRotated compresses with, trading speed for ratio.
+//if codec == nil, gzip is used. this is the default.
+func (t *EasyLog) SetCompressionCodec(codec CompressionCodec) error {
+	t.codec.Store(codecBox{codec})
+	t.nofityDelFile()
+
+	return nil
+}
+
+func (t *EasyLog) GetCompressionCodec() CompressionCodec {
+	box, _ := t.codec.Load().(codecBox)
+	if box.codec == nil {
+		return GzipCodec(gzip.DefaultCompression)
+	}
+	return box.codec
+}
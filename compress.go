@@ -0,0 +1,136 @@
+package easylog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+//compress rotated files in a background goroutine, to .gz unless
+//SetCompressionCodec picks another codec. the uncompressed file is removed
+//once compression succeeds.
+func (t *EasyLog) SetCompressRotated(enable bool) error {
+	var v int32
+	if enable {
+		v = 1
+	}
+
+	atomic.StoreInt32(&t.compress, v)
+
+	return nil
+}
+
+func (t *EasyLog) GetCompressRotated() bool {
+	return atomic.LoadInt32(&t.compress) == 1
+}
+
+//start the worker that post-processes rotated files. the serve goroutine
+//hands files over with _queueRotated and never waits for compression or
+//uploads.
+func (t *EasyLog) _initCompress() {
+	compress := func(f rotatedFile) {
+		defer func() {
+			recover()
+		}()
+
+		codec := t.GetCompressionCodec()
+		done := strings.HasSuffix(f.path, ".gz") || strings.HasSuffix(f.path, codec.Extension())
+		if t.GetCompressRotated() && !done {
+			err := _compressFile(f.path, codec)
+			t._reportError(err)
+			if err == nil {
+				f.path += codec.Extension()
+			}
+		}
+
+		t._reportError(t._updateManifest(&f))
+		t._archive(f.path)
+		t.nofityDelFile()
+	}
+
+	next := func() (rotatedFile, bool) {
+		t.rotatedMu.Lock()
+		defer t.rotatedMu.Unlock()
+
+		if len(t.rotated) == 0 {
+			return rotatedFile{}, false
+		}
+		f := t.rotated[0]
+		t.rotated = t.rotated[1:]
+		return f, true
+	}
+
+	drain := func() {
+		for f, ok := next(); ok; f, ok = next() {
+			compress(f)
+		}
+	}
+
+	t.wakeCompress, t.compressDone = t._worker(drain, t.serveDone)
+}
+
+//hand a rotated file to the compression worker without blocking
+func (t *EasyLog) _queueRotated(f rotatedFile) {
+	t.rotatedMu.Lock()
+	t.rotated = append(t.rotated, f)
+	t.rotatedMu.Unlock()
+
+	t.wakeCompress()
+}
+
+//compress path into path plus the codec's extension and remove path
+func _compressFile(path string, codec CompressionCodec) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	newPath := path + codec.Extension()
+	tmpPath := newPath + ".tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+
+	zw, err := codec.NewWriter(dst)
+	if err == nil {
+		if gz, ok := zw.(*gzip.Writer); ok {
+			gz.Name = info.Name()
+			gz.ModTime = info.ModTime()
+		}
+
+		_, err = io.Copy(zw, src)
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, newPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	src.Close()
+
+	//retention may have removed it meanwhile
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,326 @@
+package easylog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//LoadConfig reads settings from a JSON, YAML or TOML file, chosen by the
+//file extension, and applies them. only flat key/value files are supported:
+//
+//  dir: /var/log/myapp
+//  file_name: app.log
+//  max_file_size: 4194304
+//  max_file_count: 10
+//  max_file_age: 168h
+//  max_total_size: 1073741824
+//  rotate_interval: 24h
+//  flush_freq: 1s
+//  flush_every: 100
+//  rotate_name_pattern: "{name}.{date}"
+//  compress: true
+//  manifest: true
+//  level: info
+//  format: json # text, json, logfmt or csv
+//  csv_columns: "time,level,msg,user"
+//  escape: all # off, all or indent
+//  encoding: utf-16le # utf-8, utf-16le or utf-16be
+//  time_format: "2006-01-02 15:04:05"
+//  prefix: "myapp "
+//
+//keys missing from the file leave the current setting unchanged.
+func (t *EasyLog) LoadConfig(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var values map[string]string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		values, err = _parseJSONConfig(data)
+	case ".yaml", ".yml":
+		values, err = _parseFlatConfig(data, ":")
+	case ".toml":
+		values, err = _parseFlatConfig(data, "=")
+	default:
+		return fmt.Errorf("easylog: unsupported config file %q", path)
+	}
+	if err != nil {
+		return fmt.Errorf("easylog: parse config %s: %v", path, err)
+	}
+
+	if err := t._applyConfig(values); err != nil {
+		return fmt.Errorf("easylog: apply config %s: %v", path, err)
+	}
+
+	return nil
+}
+
+//re-apply the config file at path whenever it changes. the file is polled
+//every interval until the logger is closed. reload errors are reported
+//through OnError.
+func (t *EasyLog) WatchConfig(path string, interval time.Duration) error {
+	if interval < time.Millisecond*100 {
+		interval = time.Millisecond * 100
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	t.closeMu.RLock()
+	defer t.closeMu.RUnlock()
+
+	if t.closed {
+		return ErrClosed
+	}
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		tm := t.clock.NewTicker(interval)
+		defer tm.Stop()
+
+		modTime, size := info.ModTime(), info.Size()
+		for {
+			select {
+			case <-tm.C():
+			case <-t.quit:
+				return
+			}
+
+			fi, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if fi.ModTime().Equal(modTime) && fi.Size() == size {
+				continue
+			}
+			modTime, size = fi.ModTime(), fi.Size()
+
+			t._reportError(t.LoadConfig(path))
+		}
+	}()
+
+	return nil
+}
+
+func (t *EasyLog) _applyConfig(values map[string]string) error {
+	dir, name := t.GetDir()
+	_, hasDir := values["dir"]
+	_, hasName := values["file_name"]
+	if hasDir {
+		dir = values["dir"]
+	}
+	if hasName {
+		name = values["file_name"]
+	}
+	if hasDir || hasName {
+		if err := t.SetDir(dir, name); err != nil {
+			return err
+		}
+	}
+
+	for key, value := range values {
+		var err error
+
+		switch key {
+		case "dir", "file_name":
+		case "max_file_size":
+			var n int64
+			if n, err = strconv.ParseInt(value, 10, 64); err == nil {
+				err = t.SetMaxFileSize(n)
+			}
+		case "max_file_count":
+			var n int64
+			if n, err = strconv.ParseInt(value, 10, 64); err == nil {
+				err = t.SetMaxFileCount(n)
+			}
+		case "max_total_size":
+			var n int64
+			if n, err = strconv.ParseInt(value, 10, 64); err == nil {
+				err = t.SetMaxTotalSize(n)
+			}
+		case "max_file_age":
+			var d time.Duration
+			if d, err = time.ParseDuration(value); err == nil {
+				err = t.SetMaxFileAge(d)
+			}
+		case "flush_freq":
+			var d time.Duration
+			if d, err = time.ParseDuration(value); err == nil {
+				err = t.SetFlushFreq(d)
+			}
+		case "flush_every":
+			var n int
+			if n, err = strconv.Atoi(value); err == nil {
+				err = t.SetFlushEvery(n)
+			}
+		case "rotate_interval":
+			var d time.Duration
+			if d, err = time.ParseDuration(value); err == nil {
+				err = t.SetRotateInterval(d)
+			}
+		case "rotate_name_pattern":
+			err = t.SetRotateNamePattern(value)
+		case "compress":
+			var b bool
+			if b, err = strconv.ParseBool(value); err == nil {
+				err = t.SetCompressRotated(b)
+			}
+		case "manifest":
+			var b bool
+			if b, err = strconv.ParseBool(value); err == nil {
+				err = t.SetManifest(b)
+			}
+		case "level":
+			var level Level
+			if level, err = ParseLevel(value); err == nil {
+				err = t.SetLevel(level)
+			}
+		case "format":
+			switch strings.ToLower(value) {
+			case "text":
+				err = t.SetFormat(TextFormat)
+			case "json":
+				err = t.SetFormat(JSONFormat)
+			case "logfmt":
+				err = t.SetFormat(LogfmtFormat)
+			case "csv":
+				err = t.SetFormat(CSVFormat)
+			default:
+				err = fmt.Errorf("unknown format %q", value)
+			}
+		case "csv_columns":
+			var columns []string
+			for _, c := range strings.Split(value, ",") {
+				if c = strings.TrimSpace(c); c != "" {
+					columns = append(columns, c)
+				}
+			}
+			err = t.SetCSVColumns(columns...)
+		case "escape":
+			switch strings.ToLower(value) {
+			case "off":
+				err = t.SetEscapeMode(EscapeOff)
+			case "all":
+				err = t.SetEscapeMode(EscapeAll)
+			case "indent":
+				err = t.SetEscapeMode(EscapeIndent)
+			default:
+				err = fmt.Errorf("unknown escape mode %q", value)
+			}
+		case "encoding":
+			switch strings.ToLower(value) {
+			case "utf-8", "utf8":
+				err = t.SetEncoding(nil)
+			case "utf-16le", "utf-16":
+				err = t.SetEncoding(UTF16(false))
+			case "utf-16be":
+				err = t.SetEncoding(UTF16(true))
+			default:
+				err = fmt.Errorf("unknown encoding %q, other charsets are set with SetEncoding", value)
+			}
+		case "time_format":
+			err = t.SetTimeFormat(value)
+		case "ordered_timestamps":
+			var b bool
+			if b, err = strconv.ParseBool(value); err == nil {
+				err = t.SetOrderedTimestamps(b)
+			}
+		case "prefix":
+			err = t.SetPrefix(value)
+		default:
+			err = fmt.Errorf("unknown key %q", key)
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+	}
+
+	return nil
+}
+
+func _parseJSONConfig(data []byte) (map[string]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	raw := map[string]interface{}{}
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(raw))
+	for k, v := range raw {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}, nil:
+			return nil, fmt.Errorf("key %q must be a string, number or bool", k)
+		}
+		values[k] = fmt.Sprint(v)
+	}
+
+	return values, nil
+}
+
+//parse "key: value" (YAML) or "key = value" (TOML) lines. blank lines,
+//comments and TOML table headers are skipped.
+func _parseFlatConfig(data []byte, sep string) (map[string]string, error) {
+	values := map[string]string{}
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line == "---" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") {
+			continue
+		}
+
+		i := strings.Index(line, sep)
+		if i <= 0 {
+			return nil, fmt.Errorf("line %d: expected key%svalue", lineNo, sep)
+		}
+
+		key := strings.TrimSpace(line[:i])
+		value, err := _parseFlatValue(strings.TrimSpace(line[i+len(sep):]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNo, err)
+		}
+		values[key] = value
+	}
+
+	return values, sc.Err()
+}
+
+func _parseFlatValue(value string) (string, error) {
+	if strings.HasPrefix(value, `"`) {
+		end := strings.LastIndex(value, `"`)
+		if end == 0 {
+			return "", fmt.Errorf("unterminated string %s", value)
+		}
+		return strconv.Unquote(value[:end+1])
+	}
+
+	if strings.HasPrefix(value, "'") {
+		end := strings.LastIndex(value, "'")
+		if end == 0 {
+			return "", fmt.Errorf("unterminated string %s", value)
+		}
+		return value[1:end], nil
+	}
+
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+
+	return value, nil
+}
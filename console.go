@@ -0,0 +1,103 @@
+package easylog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	consoleOff = iota
+	//console in addition to the log file
+	consoleTee
+	//console instead of the log file
+	consoleExclusive
+)
+
+var levelColors = [numLevels]string{
+	DebugLevel: "\x1b[90m",
+	InfoLevel:  "\x1b[36m",
+	WarnLevel:  "\x1b[33m",
+	ErrorLevel: "\x1b[31m",
+	PanicLevel: "\x1b[1;31m",
+	FatalLevel: "\x1b[1;31m",
+}
+
+//stderr is shared by every logger in the process
+var console = struct {
+	sync.Mutex
+	w io.Writer
+}{w: os.Stderr}
+
+//print leveled entries to stderr with colored levels and aligned columns,
+//for local development. entries still go to the log file unless consoleOnly
+//is true, in which case raw Write data is copied to stderr as well and
+//nothing is written to the log file. colors are disabled when the NO_COLOR
+//environment variable is set.
+func (t *EasyLog) SetDevelopmentMode(enable bool, consoleOnly bool) error {
+	mode := int32(consoleOff)
+	if enable && consoleOnly {
+		mode = consoleExclusive
+	} else if enable {
+		mode = consoleTee
+	}
+
+	atomic.StoreInt32(&t.console, mode)
+
+	return nil
+}
+
+func (t *EasyLog) _consoleMode() int32 {
+	return atomic.LoadInt32(&t.console)
+}
+
+func (t *EasyLog) _writeConsole(now time.Time, level Level, msg string, fields Fields) {
+	color, reset, dim := levelColors[level], "\x1b[0m", "\x1b[2m"
+	if os.Getenv("NO_COLOR") != "" {
+		color, reset, dim = "", "", ""
+	}
+
+	buf := t._getBuffer()
+	defer t._putBuffer(buf)
+
+	buf.WriteString(dim)
+	_writeTime(buf, now, "15:04:05.000")
+	buf.WriteString(reset)
+	buf.WriteByte(' ')
+	buf.WriteString(color)
+	fmt.Fprintf(buf, "%-5s", level.String())
+	buf.WriteString(reset)
+	buf.WriteByte(' ')
+
+	msg = strings.TrimRight(msg, "\n")
+	if len(fields) > 0 {
+		//line up the fields of consecutive entries
+		fmt.Fprintf(buf, "%-40s", msg)
+	} else {
+		buf.WriteString(msg)
+	}
+
+	for _, k := range _sortedKeys(fields) {
+		buf.WriteByte(' ')
+		buf.WriteString(dim)
+		buf.WriteString(k)
+		buf.WriteString(reset)
+		buf.WriteByte('=')
+		fmt.Fprint(buf, _fieldValue(fields[k]))
+	}
+	buf.WriteByte('\n')
+
+	t._redact(buf)
+	_writeStderr(buf)
+}
+
+func _writeStderr(buf *bytes.Buffer) {
+	console.Lock()
+	console.w.Write(buf.Bytes())
+	console.Unlock()
+}
@@ -0,0 +1,81 @@
+package easylog
+
+import "context"
+
+type fieldsKey struct{}
+
+//return a copy of ctx carrying fields. Logger.WithContext picks them up, so a
+//request id stored once at the edge of a service appears on every entry.
+//fields already stored in ctx are kept unless overwritten.
+func ContextWithFields(ctx context.Context, fields Fields) context.Context {
+	merged := make(Fields, len(fields))
+	for k, v := range FieldsFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+//return a copy of ctx carrying a single field
+func ContextWithField(ctx context.Context, key string, value interface{}) context.Context {
+	return ContextWithFields(ctx, Fields{key: value})
+}
+
+//return the fields stored in ctx by ContextWithFields. the result must not be
+//modified.
+func FieldsFromContext(ctx context.Context) Fields {
+	if ctx == nil {
+		return nil
+	}
+
+	fields, _ := ctx.Value(fieldsKey{}).(Fields)
+
+	return fields
+}
+
+//return a Logger that attaches the fields carried by ctx
+func (t *EasyLog) WithContext(ctx context.Context) *Logger {
+	return (&Logger{log: t}).WithContext(ctx)
+}
+
+//return a Logger that attaches a single field
+func (t *EasyLog) WithField(key string, value interface{}) *Logger {
+	return (&Logger{log: t}).WithField(key, value)
+}
+
+//return a new Logger with the fields carried by ctx merged into the existing ones
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	return l.WithFields(FieldsFromContext(ctx))
+}
+
+//return a new Logger with key set to value
+func (l *Logger) WithField(key string, value interface{}) *Logger {
+	return l.WithFields(Fields{key: value})
+}
+
+//like NewLogWithOptions, but the log is closed when ctx is canceled: pending
+//entries are flushed and the serve, cleanup and sink goroutines stop, as if
+//Close was called. this ties the log to a service's lifecycle.
+func NewLogWithContext(ctx context.Context, opts Options) (*EasyLog, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ins, err := NewLogWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			ins.Close()
+		case <-ins.serveDone:
+		}
+	}()
+
+	return ins, nil
+}
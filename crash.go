@@ -0,0 +1,76 @@
+package easylog
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"sync"
+	"syscall"
+	"time"
+)
+
+//log a panic together with its stack trace, flush everything to disk, then
+//panic again so the program still crashes. use it as the first deferred call
+//of main and of goroutines:
+//
+//  defer log.RecoverAndLog()
+func (t *EasyLog) RecoverAndLog() {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	t._logPanic(r, debug.Stack())
+
+	panic(r)
+}
+
+func (t *EasyLog) _logPanic(r interface{}, stack []byte) {
+	buf := t._getBuffer()
+	if !t._encode(buf, t._now(), ErrorLevel, fmt.Sprintf("panic: %v", r), Fields{"stack": string(stack)}) {
+		t._putBuffer(buf)
+		return
+	}
+	//panic messages and stacks can hold secrets like any other entry
+	t._redact(buf)
+	t._shard(nil)._writeSync(buf)
+}
+
+//flush and close the logger when one of sigs is received, then deliver the
+//signal again with its default behavior so the process exits as it would
+//have. with no arguments, os.Interrupt and SIGTERM are handled.
+//call the returned function to stop handling the signals.
+func (t *EasyLog) CloseOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		defer signal.Stop(ch)
+
+		select {
+		case sig := <-ch:
+			t.Infof("received signal %v, closing log", sig)
+			t.Close()
+
+			signal.Reset(sig)
+			if p, err := os.FindProcess(os.Getpid()); err == nil && p.Signal(sig) == nil {
+				//give the runtime a moment to deliver the signal
+				time.Sleep(time.Second)
+			}
+			os.Exit(1)
+		case <-done:
+		case <-t.quit:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
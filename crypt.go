@@ -0,0 +1,142 @@
+package easylog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+//encrypted log files are a sequence of records, one per flushed batch:
+//
+//  length  uint32, big endian, of nonce and sealed data
+//  nonce   12 bytes
+//  sealed  AES-GCM ciphertext and tag of the batch
+//
+//records are independent, so appending after a restart or rotating between
+//batches needs no extra bookkeeping.
+const maxCryptRecord = 64 * 1024 * 1024
+
+var ErrBadRecord = errors.New("easylog: corrupt encrypted record")
+
+type aeadBox struct {
+	aead cipher.AEAD
+}
+
+//encrypt log files at rest with AES-GCM. key must be 16, 24 or 32 bytes for
+//AES-128, AES-192 or AES-256. pass nil to stop encrypting. outputs added by
+//AddOutput still receive plain text. read encrypted files with
+//NewDecryptReader or the easylog-decrypt command.
+func (t *EasyLog) SetEncryptionKey(key []byte) error {
+	if key == nil {
+		t.crypt.Store(aeadBox{})
+		return nil
+	}
+
+	aead, err := _newAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	t.crypt.Store(aeadBox{aead: aead})
+
+	return nil
+}
+
+func _newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("easylog: encryption key: %v", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+//replace data with its encrypted record if encryption is enabled
+func (t *EasyLog) _encrypt(data *bytes.Buffer) error {
+	box, _ := t.crypt.Load().(aeadBox)
+	if box.aead == nil {
+		return nil
+	}
+
+	nonce := make([]byte, box.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	sealed := box.aead.Seal(nil, nonce, data.Bytes(), nil)
+
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(nonce)+len(sealed)))
+
+	data.Reset()
+	data.Write(header[:])
+	data.Write(nonce)
+	data.Write(sealed)
+
+	return nil
+}
+
+type decryptReader struct {
+	r    *bufio.Reader
+	aead cipher.AEAD
+	buf  []byte
+}
+
+//return a reader of the plain text of an encrypted log file
+func NewDecryptReader(r io.Reader, key []byte) (io.Reader, error) {
+	aead, err := _newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptReader{r: bufio.NewReader(r), aead: aead}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if err := d._next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+
+	return n, nil
+}
+
+func (d *decryptReader) _next() error {
+	var header [4]byte
+	if _, err := io.ReadFull(d.r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return ErrBadRecord
+		}
+		return err
+	}
+
+	size := binary.BigEndian.Uint32(header[:])
+	if size < uint32(d.aead.NonceSize()) || size > maxCryptRecord {
+		return ErrBadRecord
+	}
+
+	record := make([]byte, size)
+	if _, err := io.ReadFull(d.r, record); err != nil {
+		return ErrBadRecord
+	}
+
+	nonce, sealed := record[:d.aead.NonceSize()], record[d.aead.NonceSize():]
+	plain, err := d.aead.Open(sealed[:0], nonce, sealed, nil)
+	if err != nil {
+		return fmt.Errorf("easylog: decrypt record: %v", err)
+	}
+
+	d.buf = plain
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package easylog
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+var defaultCSVColumns = []string{"time", "level", "msg"}
+
+//set the columns of CSVFormat entries, in order. "time", "level", "msg" and
+//"prefix" name the parts of the entry, any other name the field of that key.
+//fields that aren't a column are left out, missing ones leave the cell empty.
+//with no columns, "time", "level" and "msg" are written.
+//unless SetFileHeader is used, every new file starts with the column names.
+func (t *EasyLog) SetCSVColumns(columns ...string) error {
+	for _, c := range columns {
+		if c == "" {
+			return fmt.Errorf("easylog: empty csv column name")
+		}
+	}
+
+	t.encMu.Lock()
+	defer t.encMu.Unlock()
+
+	cfg := t._encodeConfig()
+	cfg.csvColumns = append([]string(nil), columns...)
+	t.encCfg.Store(cfg)
+
+	return nil
+}
+
+func (t *EasyLog) GetCSVColumns() []string {
+	return append([]string(nil), t._encodeConfig()._csvColumns()...)
+}
+
+func (cfg encodeConfig) _csvColumns() []string {
+	if len(cfg.csvColumns) == 0 {
+		return defaultCSVColumns
+	}
+	return cfg.csvColumns
+}
+
+//one row per entry, quoted as described in RFC 4180. line breaks in a value
+//are written as \r and \n, so a record never spans lines.
+type csvEncoder struct {
+	cfg encodeConfig
+}
+
+func (enc csvEncoder) Encode(buf *bytes.Buffer, e *Entry) error {
+	cfg := enc.cfg
+	layout := cfg.timeFormat
+	if layout == "" {
+		layout = "2006-01-02 15:04:05.000"
+	}
+
+	for i, c := range cfg._csvColumns() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		switch c {
+		case "time":
+			_writeCSVValue(buf, e.Time.Format(layout))
+		case "level":
+			buf.WriteString(e.Level.String())
+		case "msg":
+			_writeCSVValue(buf, strings.TrimRight(e.Message, "\n"))
+		case "prefix":
+			_writeCSVValue(buf, strings.TrimSpace(cfg.prefix))
+		default:
+			if v, ok := e.Fields[c]; ok {
+				_writeCSVValue(buf, fmt.Sprint(_fieldValue(v)))
+			}
+		}
+	}
+
+	buf.WriteByte('\n')
+
+	return nil
+}
+
+//the row of column names, used as the header of CSV files
+func (t *EasyLog) _csvHeader() []byte {
+	var buf bytes.Buffer
+	for i, c := range t._encodeConfig()._csvColumns() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		_writeCSVValue(&buf, c)
+	}
+
+	return buf.Bytes()
+}
+
+var csvEscaper = strings.NewReplacer(`"`, `""`, "\r", `\r`, "\n", `\n`)
+
+func _writeCSVValue(buf *bytes.Buffer, v string) {
+	if v == "" || !strings.ContainsAny(v, "\",\r\n") && v[0] != ' ' && v[len(v)-1] != ' ' {
+		buf.WriteString(v)
+		return
+	}
+
+	buf.WriteByte('"')
+	csvEscaper.WriteString(buf, v)
+	buf.WriteByte('"')
+}
@@ -0,0 +1,51 @@
+package easylog
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const dailyDirLayout = "2006-01-02"
+
+//store the active file in a subdirectory of the log directory named after
+//the current day, as <dir>/2024-05-17/<name>. a new subdirectory is created
+//at midnight. retention treats the files of earlier days as rotated and
+//deletes day directories once they are empty.
+func (t *EasyLog) SetDailyDirs(enable bool) error {
+	t.mu.Lock()
+	t.dailyDirs = enable
+	t.mu.Unlock()
+
+	return nil
+}
+
+func (t *EasyLog) GetDailyDirs() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.dailyDirs
+}
+
+//remove day directories other than the current one once they are empty
+func _removeEmptyDays(cfg fileConfig) {
+	entries, err := os.ReadDir(cfg.root)
+	if err != nil {
+		return
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(dailyDirLayout, e.Name()); err != nil {
+			continue
+		}
+
+		path := filepath.Join(cfg.root, e.Name())
+		if path != cfg.dir {
+			//fails for directories that still hold files
+			os.Remove(path)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package easylog
+
+import (
+	"sync"
+	"time"
+)
+
+//deduper collapses consecutive repeats of a leveled message. the first one
+//is logged, the repeats within the window after it are counted and logged
+//once as the same message with a "repeated" field.
+type deduper struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	last    sampleKey
+	start   time.Time
+	repeats int
+	fields  Fields
+	timer   timer
+}
+
+//collapse identical consecutive leveled messages, e.g. from a retry loop.
+//a message is logged, its repeats within window are counted, and when the
+//window ends or another message is logged, one more entry with the same
+//message and a "repeated" field holding the count is written. unlike
+//SetSampling, every repeat is accounted for. Write is not affected.
+//if window == 0, deduplication is disabled.
+func (t *EasyLog) SetDedupWindow(window time.Duration) error {
+	if window < 0 {
+		window = 0
+	}
+
+	var d *deduper
+	if window > 0 {
+		d = &deduper{window: window}
+	}
+
+	t.dedupMu.Lock()
+	old := t._deduper()
+	t.dedup.Store(d)
+	t.dedupMu.Unlock()
+
+	if old != nil {
+		old.flush(t)
+	}
+
+	return nil
+}
+
+func (t *EasyLog) GetDedupWindow() time.Duration {
+	if d := t._deduper(); d != nil {
+		return d.window
+	}
+	return 0
+}
+
+func (t *EasyLog) _deduper() *deduper {
+	d, _ := t.dedup.Load().(*deduper)
+	return d
+}
+
+func (d *deduper) allow(t *EasyLog, now time.Time, level Level, msg string, fields Fields) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := sampleKey{level: level, msg: msg}
+	if key == d.last && !d.start.IsZero() && now.Sub(d.start) < d.window {
+		d.repeats++
+		d.fields = fields
+		if d.timer == nil {
+			start := d.start
+			d.timer = t.clock.AfterFunc(d.window-now.Sub(start), func() {
+				d.mu.Lock()
+				defer d.mu.Unlock()
+				if d.start.Equal(start) {
+					d._emit(t)
+				}
+			})
+		}
+		return false
+	}
+
+	d._emit(t)
+	d.last = key
+	d.start = now
+
+	return true
+}
+
+//log the pending repeat count, e.g. before the logger closes
+func (d *deduper) flush(t *EasyLog) {
+	d.mu.Lock()
+	d._emit(t)
+	d.mu.Unlock()
+}
+
+//must be called with d.mu held
+func (d *deduper) _emit(t *EasyLog) {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+
+	if d.repeats == 0 {
+		return
+	}
+
+	fields := _copyFields(d.fields)
+	fields["repeated"] = d.repeats
+	d.repeats = 0
+	d.fields = nil
+
+	t._dispatch(t._now(), d.last.level, d.last.msg, fields)
+}
@@ -2,6 +2,7 @@ package easylog
 
 import (
 	"bytes"
+	"container/list"
 	"fmt"
 	"io"
 	"os"
@@ -9,18 +10,161 @@ import (
 	"regexp"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type EasyLog struct {
-	SaveDir       string
-	FileName      string
-	MaxFileSize   int64
-	MaxFileCount  int64
-	FlushFreq     time.Duration
-	pool          sync.Pool
-	Pipe          chan *bytes.Buffer
+	//64-bit atomics first, to keep them aligned on 32-bit platforms
+	counters      counters
+	dropped       uint64
+	discarded     uint64
+	maxBufferCap  int64
+	maxInFlight   int64
+	maxFileAge    int64
+	maxTotalSize  int64
+	rotateEvery   int64
+	maxEntrySize  int64
+	writeTimeout  int64
+	batchSize     int64
+	maxBatchDelay int64
+	flushEvery    int64
+	retryMax      int64
+	fileCheck     int64
+	queueFired    int64
+	level         int32
+	queuePolicy   int32
+	format        int32
+	reportCaller  int32
+	callerSkip    int32
+	console       int32
+	breaker       int32
+	multiProcess  int32
+	hostFields    int32
+	audit         int32
+	maxOpenKeys   int32
+	stackLevel    int32
+	stackDepth    int32
+	encMu         sync.Mutex
+	encCfg        atomic.Value
+	encoder       atomic.Value
+	mu            sync.RWMutex
+	saveDir       string
+	fileName      string
+	maxFileSize   int64
+	maxFileCount  int64
+	rotatePattern string
+	fallbackDir   string
+	spillOffset   int64
+	dailyDirs     bool
+	lazyCreate    bool
+	dirMode       os.FileMode
+	fileMode      os.FileMode
+	flushFreq     time.Duration
+	period        time.Time
+	periodLen     time.Duration
+	file          *os.File
+	filePath      string
+	fileSize      int64
+	headerSize    int64
+	streamSize    int64
+	streaming     bool
+	streamStarted bool
+	batchEntries  int
+	fileHeader    atomic.Value
+	fileFooter    atomic.Value
+	lockFile      *os.File
+	sink          Sink
+	sinkOpen      bool
+	pool          *bufferPool
+	noPool        int32
+	pipe          chan *bytes.Buffer
 	nofityDelFile func()
+	compress      int32
+	ordered       int32
+	stampMu       sync.Mutex
+	lastStamp     int64
+	rotatedMu     sync.Mutex
+	rotated       []rotatedFile
+	manifest      int32
+	manifestMu    sync.Mutex
+	spanPath      string
+	fileFirst     time.Time
+	fileLast      time.Time
+	wakeCompress  func()
+	renameFails   int
+	renameRetry   time.Time
+	compressDone  chan struct{}
+	retryBuf      bytes.Buffer
+	flushReq      chan chan error
+	freqReq       chan struct{}
+	syncReq       chan syncRequest
+	cmdReq        chan command
+	synchronous   int32
+	syncPolicy    atomic.Value
+	dirty         bool
+	lastSync      time.Time
+	auditInit     bool
+	auditSeq      uint64
+	auditPrev     []byte
+	quit          chan struct{}
+	serveDone     chan struct{}
+	wg            sync.WaitGroup
+	closeMu       sync.RWMutex
+	closed        bool
+	closeErr      error
+	onError       atomic.Value
+	lastErr       atomic.Value
+	exitFn        atomic.Value
+	outMu         sync.Mutex
+	outputs       []io.Writer
+	teeMu         sync.Mutex
+	recent        ring
+	routeMu       sync.Mutex
+	routes        atomic.Value
+	memMu         sync.Mutex
+	memCond       *sync.Cond
+	inFlight      int64
+	peakInFlight  int64
+	sampling      atomic.Value
+	dedupMu       sync.Mutex
+	dedup         atomic.Value
+	crypt         atomic.Value
+	codec         atomic.Value
+	charset       atomic.Value
+	archiver      atomic.Value
+	moduleMu      sync.Mutex
+	moduleLevels  atomic.Value
+	alertMu       sync.Mutex
+	alertList     atomic.Value
+	alertCh       chan Entry
+	wakeAlerts    func()
+	sched         *Scheduler
+	jobs          []*serialJob
+	lastAgeCheck  time.Time
+	lastFileCheck time.Time
+	recovered     string
+	globalMu      sync.Mutex
+	userGlobals   Fields
+	globals       atomic.Value
+	clock         clock
+	fs            platform
+	keyMu         sync.Mutex
+	keyLogs       map[string]*keyLog
+	keyOpen       *list.List
+	categoryMu    sync.Mutex
+	categories    atomic.Value
+	shardMu       sync.Mutex
+	shards        atomic.Value
+	owner         *EasyLog
+	shadowMu      sync.Mutex
+	shadow        atomic.Value
+	separator     atomic.Value
+	queueWatch    atomic.Value
+	hookMu        sync.Mutex
+	hookList      atomic.Value
+	redactMu      sync.Mutex
+	redactList    atomic.Value
 }
 
 func NewLog(buflen int, FlushFreq time.Duration) *EasyLog {
@@ -32,37 +176,88 @@ func NewLog(buflen int, FlushFreq time.Duration) *EasyLog {
 		FlushFreq = time.Millisecond * 10
 	}
 
+	ins, _ := NewLogWithOptions(Options{BufferLen: buflen, FlushFreq: FlushFreq})
+
+	return ins
+}
+
+func _newLog(buflen int, FlushFreq time.Duration) *EasyLog {
 	ins := &EasyLog{}
-	ins.SaveDir = ""
-	ins.FileName = "log.txt"
-	ins.MaxFileSize = 1024 * 1024 * 4
-	ins.MaxFileCount = 0
-	ins.FlushFreq = FlushFreq
-	ins.pool.New = func() interface{} {
-		c := &bytes.Buffer{}
-		return c
-	}
+	ins.saveDir = ""
+	ins.fileName = "log.txt"
+	ins.maxFileSize = 1024 * 1024 * 4
+	ins.maxFileCount = 0
+	ins.rotatePattern = defaultRotatePattern
+	ins.dirMode = 0755
+	ins.fileMode = 0644
+	ins.level = int32(DebugLevel)
+	ins.clock = realClock{}
+	ins.flushFreq = FlushFreq
+	ins.pool = _newBufferPool()
 
-	ins.Pipe = make(chan *bytes.Buffer, buflen)
-	ins._initFileRemove()
+	ins.maxBufferCap = defaultMaxBufferCap
+	ins.batchSize = defaultBatchSize
+	ins.retryMax = defaultRetryBuffer
+	ins.maxOpenKeys = defaultMaxOpenKeys
+	ins.stackLevel = int32(StacktraceOff)
+	ins.stackDepth = defaultStacktraceDepth
+	ins.fs = osPlatform
+	ins.fileCheck = int64(defaultFileCheck)
+	ins.memCond = sync.NewCond(&ins.memMu)
 
-	go ins._serveLog()
+	ins.pipe = make(chan *bytes.Buffer, buflen)
+	ins.flushReq = make(chan chan error)
+	ins.freqReq = make(chan struct{}, 1)
+	ins.syncReq = make(chan syncRequest)
+	ins.cmdReq = make(chan command)
+	ins.quit = make(chan struct{})
+	ins.serveDone = make(chan struct{})
 
 	return ins
 }
 
-//set where to store logs, and the log file's name
+func (t *EasyLog) _start() {
+	t._initCompress()
+	t._initFileRemove()
+	t._initAlerts()
+
+	t.wg.Add(1)
+	go t._serveLog()
+}
+
+//set where to store logs, and the log file's name. files an earlier run
+//left behind are checked on the next flush: an active file beyond the max
+//file size is rotated, and interrupted compressions and uploads are redone.
 func (t *EasyLog) SetDir(szDir string, FileName string) error {
-	if err := os.MkdirAll(szDir, 666); err != nil {
-		return err
+	t.mu.RLock()
+	dirMode, lazy := t.dirMode, t.lazyCreate
+	t.mu.RUnlock()
+
+	if !lazy {
+		if err := os.MkdirAll(szDir, dirMode); err != nil {
+			return err
+		}
 	}
 
-	t.SaveDir = szDir
-	t.FileName = FileName
+	t.mu.Lock()
+	t.saveDir = szDir
+	t.fileName = FileName
+	t.mu.Unlock()
+
+	//files rotated under the new name may be due for cleanup
+	t.nofityDelFile()
 
 	return nil
 }
 
+//get the log directory and the log file's name
+func (t *EasyLog) GetDir() (szDir string, FileName string) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.saveDir, t.fileName
+}
+
 //set single file max size. if the file size exceeds MaxFileSize, then a new file
 //will be created to store log info
 func (t *EasyLog) SetMaxFileSize(MaxFileSize int64) error {
@@ -70,11 +265,56 @@ func (t *EasyLog) SetMaxFileSize(MaxFileSize int64) error {
 		MaxFileSize = 1024 * 1024
 	}
 
-	t.MaxFileSize = MaxFileSize
+	t.mu.Lock()
+	t.maxFileSize = MaxFileSize
+	t.mu.Unlock()
+
+	return nil
+}
+
+func (t *EasyLog) GetMaxFileSize() int64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.maxFileSize
+}
+
+//set max age of rotated log files. files whose modification time is older
+//than MaxFileAge are deleted regardless of MaxFileCount.
+//if MaxFileAge == 0, files are never deleted by age.
+func (t *EasyLog) SetMaxFileAge(MaxFileAge time.Duration) error {
+	if MaxFileAge < 0 {
+		MaxFileAge = 0
+	}
+
+	atomic.StoreInt64(&t.maxFileAge, int64(MaxFileAge))
+	t.nofityDelFile()
 
 	return nil
 }
 
+func (t *EasyLog) GetMaxFileAge() time.Duration {
+	return time.Duration(atomic.LoadInt64(&t.maxFileAge))
+}
+
+//set a quota for the aggregate size of the active and rotated log files.
+//the oldest rotated files are deleted until the total is within MaxTotalSize.
+//if MaxTotalSize == 0, total size is not limited.
+func (t *EasyLog) SetMaxTotalSize(MaxTotalSize int64) error {
+	if MaxTotalSize < 0 {
+		MaxTotalSize = 0
+	}
+
+	atomic.StoreInt64(&t.maxTotalSize, MaxTotalSize)
+	t.nofityDelFile()
+
+	return nil
+}
+
+func (t *EasyLog) GetMaxTotalSize() int64 {
+	return atomic.LoadInt64(&t.maxTotalSize)
+}
+
 //set max log file count
 //if MaxFileCount == 0, no file count limited.
 //if MaxFileCount > 0 and actual file count > MaxFileCount, then the earliest log file will be deleted.
@@ -83,184 +323,797 @@ func (t *EasyLog) SetMaxFileCount(MaxFileCount int64) error {
 		MaxFileCount = 0
 	}
 
-	t.MaxFileCount = MaxFileCount
+	t.mu.Lock()
+	t.maxFileCount = MaxFileCount
+	t.mu.Unlock()
+	t.nofityDelFile()
 
 	return nil
 }
 
+func (t *EasyLog) GetMaxFileCount() int64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.maxFileCount
+}
+
+//set the permissions of created log directories and files. the process
+//umask still applies. the defaults are 0755 and 0644.
+func (t *EasyLog) SetPermissions(dirMode, fileMode os.FileMode) error {
+	if dirMode == 0 {
+		dirMode = 0755
+	}
+
+	if fileMode == 0 {
+		fileMode = 0644
+	}
+
+	t.mu.Lock()
+	t.dirMode = dirMode.Perm()
+	t.fileMode = fileMode.Perm()
+	t.mu.Unlock()
+
+	return nil
+}
+
+func (t *EasyLog) GetPermissions() (dirMode, fileMode os.FileMode) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.dirMode, t.fileMode
+}
+
+//set the interval of periodic flushes. it takes effect right away, so a
+//service can switch between low latency and fewer writes at runtime.
+//intervals below 10ms are raised to 10ms.
+func (t *EasyLog) SetFlushFreq(FlushFreq time.Duration) error {
+	if FlushFreq < time.Millisecond*10 {
+		FlushFreq = time.Millisecond * 10
+	}
+
+	t.mu.Lock()
+	t.flushFreq = FlushFreq
+	t.mu.Unlock()
+
+	//the serve goroutine restarts its ticker
+	select {
+	case t.freqReq <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+//get the interval of periodic flushes
+func (t *EasyLog) GetFlushFreq() time.Duration {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.flushFreq
+}
+
+//snapshot of the settings used by a single write or cleanup pass
+type fileConfig struct {
+	//the configured directory, and the one holding the active file. they
+	//differ when daily directories are enabled.
+	root        string
+	dir         string
+	daily       bool
+	lazy        bool
+	dirMode     os.FileMode
+	name        string
+	pattern     string
+	maxSize     int64
+	maxCount    int64
+	fileMode    os.FileMode
+	fallbackDir string
+	//the extension of compressed rotated files
+	compressExt string
+}
+
+func (t *EasyLog) _fileConfig() fileConfig {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	dir := t.saveDir
+	if t.dailyDirs {
+		dir = filepath.Join(t.saveDir, t._now().Format(dailyDirLayout))
+	}
+
+	return fileConfig{
+		root:        t.saveDir,
+		dir:         dir,
+		daily:       t.dailyDirs,
+		lazy:        t.lazyCreate,
+		dirMode:     t.dirMode,
+		name:        t.fileName,
+		pattern:     t.rotatePattern,
+		maxSize:     t.maxFileSize,
+		maxCount:    t.maxFileCount,
+		fileMode:    t.fileMode,
+		fallbackDir: t.fallbackDir,
+		compressExt: t.GetCompressionCodec().Extension(),
+	}
+}
+
+//queue p for writing as one line, a newline is added if p lacks one.
+//the line is never split, not by other writers nor across a rotation. p is
+//queued even if the last flush failed, but that error is returned, see
+//LastError. buffers come from a pool, so Write does not allocate once the
+//pool is warm.
 func (t *EasyLog) Write(p []byte) (n int, err error) {
-	buf := t.pool.Get().(*bytes.Buffer)
-	buf.Reset()
-	n, err = buf.Write(p)
+	atomic.AddUint64(&t.counters.writes, 1)
+
+	buf := t._getBuffer()
+	t._writeEntry(buf, p)
+	t._redact(buf)
+	n = len(p)
 
-	t.Pipe <- buf
+	if t._consoleMode() == consoleExclusive {
+		_writeStderr(buf)
+		t._putBuffer(buf)
+		return
+	}
 
-	return
+	if err := t._shard(nil)._enqueue(buf); err != nil {
+		return 0, err
+	}
+
+	return n, t.LastError()
 }
 
-func (t *EasyLog) _initFileRemove() {
-	ch := make(chan int, 1)
+//like Write, but s is copied straight into the pooled buffer, without
+//converting it to a []byte first
+func (t *EasyLog) WriteString(s string) (n int, err error) {
+	atomic.AddUint64(&t.counters.writes, 1)
+
+	buf := t._getBuffer()
+	buf.WriteString(s)
+	t._frameEntry(buf)
+	t._redact(buf)
+	n = len(s)
+
+	if t._consoleMode() == consoleExclusive {
+		_writeStderr(buf)
+		t._putBuffer(buf)
+		return
+	}
+
+	if err := t._shard(nil)._enqueue(buf); err != nil {
+		return 0, err
+	}
+
+	return n, t.LastError()
+}
+
+//write several entries like Write, queued together with one channel send in
+//one pooled buffer, for callers that collect entries anyway, e.g. per
+//request. the entries are copied, so their memory can be reused once it
+//returns, and stay together and in order. each counts as a Write in Stats.
+//in audit mode the entries are queued one by one, to chain them separately.
+func (t *EasyLog) WriteBatch(entries [][]byte) error {
+	if t.GetAuditMode() {
+		for _, p := range entries {
+			if _, err := t.Write(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	atomic.AddUint64(&t.counters.writes, uint64(len(entries)))
+
+	buf := t._getBuffer()
+	for _, p := range entries {
+		start := buf.Len()
+		buf.Write(p)
+		t._frameTail(buf, start)
+		t._redactTail(buf, start)
+	}
+
+	if t._consoleMode() == consoleExclusive {
+		_writeStderr(buf)
+		t._putBuffer(buf)
+		return nil
+	}
+
+	if err := t._shard(nil)._enqueue(buf); err != nil {
+		return err
+	}
+
+	return t.LastError()
+}
+
+//write all pending log data to disk. it returns the error of the write, if any.
+func (t *EasyLog) Flush() error {
+	t.closeMu.RLock()
+	if t.closed {
+		t.closeMu.RUnlock()
+		return ErrClosed
+	}
+
+	ch := make(chan error, 1)
+	t.flushReq <- ch
+	t.closeMu.RUnlock()
+
+	err := <-ch
+	if sh := t._shadow(); sh != nil {
+		sh.Flush()
+	}
+	for _, child := range t._categories() {
+		child.Flush()
+	}
+	for _, child := range t._keyLoggers() {
+		child.Flush()
+	}
+	for _, child := range t._shardLogs() {
+		child.Flush()
+	}
+
+	return err
+}
+
+//flush pending log data and stop all background goroutines. any later
+//Write returns ErrClosed. it returns the error of the final write, if any.
+func (t *EasyLog) Close() error {
+	if d := t._deduper(); d != nil {
+		d.flush(t)
+	}
+
+	t.closeMu.Lock()
+	if t.closed {
+		t.closeMu.Unlock()
+		return ErrClosed
+	}
+	t.closed = true
+	t.closeMu.Unlock()
+
+	close(t.quit)
+	t.wg.Wait()
+	for _, j := range t.jobs {
+		j.wait()
+	}
+
+	if sh := t._shadow(); sh != nil {
+		sh.Close()
+	}
+
+	t.categoryMu.Lock()
+	for _, child := range t._categories() {
+		child.Close()
+	}
+	t.categoryMu.Unlock()
+
+	for _, child := range t._keyLoggers() {
+		child.Close()
+	}
+
+	t.shardMu.Lock()
+	for _, child := range t._shardLogs() {
+		child.Close()
+	}
+	t.shardMu.Unlock()
+
+	return t.closeErr
+}
+
+//a function run by the serve goroutine, which owns the log file
+type command struct {
+	fn   func(data *bytes.Buffer) error
+	done chan error
+}
+
+//run fn on the serve goroutine and wait for its result. data holds the
+//entries cached since the last flush.
+func (t *EasyLog) _do(fn func(data *bytes.Buffer) error) error {
+	t.closeMu.RLock()
+	if t.closed {
+		t.closeMu.RUnlock()
+		return ErrClosed
+	}
+
+	cmd := command{fn: fn, done: make(chan error, 1)}
+	t.cmdReq <- cmd
+	t.closeMu.RUnlock()
+
+	return <-cmd.done
+}
 
+func (t *EasyLog) _enqueue(buf *bytes.Buffer) error {
+	if t.GetSynchronous() {
+		return t._writeSync(buf)
+	}
+
+	t.closeMu.RLock()
+	defer t.closeMu.RUnlock()
+
+	if t.closed {
+		t._putBuffer(buf)
+		return ErrClosed
+	}
+
+	policy := t.GetQueuePolicy()
+	size := int64(buf.Len())
+	timeout := t.GetWriteTimeout()
+
+	if !t._reserve(size, policy == Block && timeout == 0) {
+		t._putBuffer(buf)
+		atomic.AddUint64(&t.dropped, 1)
+		return nil
+	}
+
+	t._watchQueue()
+
+	switch policy {
+	case DropNewest:
+		select {
+		case t.pipe <- buf:
+		default:
+			t._release(size)
+			t._putBuffer(buf)
+			atomic.AddUint64(&t.dropped, 1)
+		}
+	case DropOldest:
+		for {
+			select {
+			case t.pipe <- buf:
+				return nil
+			default:
+			}
+
+			select {
+			case old := <-t.pipe:
+				t._release(int64(old.Len()))
+				t._putBuffer(old)
+				atomic.AddUint64(&t.dropped, 1)
+			default:
+			}
+		}
+	default:
+		if timeout == 0 {
+			t.pipe <- buf
+		} else if !t._sendTimeout(buf, timeout) {
+			t._release(size)
+			t._putBuffer(buf)
+			atomic.AddUint64(&t.dropped, 1)
+		}
+	}
+
+	return nil
+}
+
+//a rotated file found by the cleanup pass
+type oldFile struct {
+	path string
+	os.FileInfo
+}
+
+func (t *EasyLog) _initFileRemove() {
+	//what _rotatedMatcher builds re from
+	type matchKey struct {
+		name, pattern, compressExt string
+	}
+	var re *regexp.Regexp
+	var matched matchKey
 	cleanFile := func() {
 		defer func() {
 			recover()
 		}()
 
-		expr := fmt.Sprintf(`%s\.\d{14}`, t.FileName)
-		re, _ := regexp.Compile(expr)
-		flist := make([]string, 0, 100)
-		filepath.Walk(t.SaveDir, func(path string, fi os.FileInfo, err error) error {
+		cfg := t._fileConfig()
+		if key := (matchKey{cfg.name, cfg.pattern, cfg.compressExt}); re == nil || key != matched {
+			//the name, pattern or codec changed since the last pass
+			re = _rotatedMatcher(cfg)
+			matched = key
+		}
+		flist := make([]oldFile, 0, 100)
+		filepath.Walk(cfg.root, func(path string, fi os.FileInfo, err error) error {
 			if nil == fi {
 				return nil
 			}
 
+			//other directories, e.g. of ForKey loggers, hold files that
+			//aren't ours. only day directories are searched.
 			if fi.IsDir() {
-				return nil
+				if path == cfg.root {
+					return nil
+				}
+				if _, err := time.Parse(dailyDirLayout, fi.Name()); cfg.daily && err == nil && filepath.Dir(path) == cfg.root {
+					return nil
+				}
+				return filepath.SkipDir
 			}
 
-			if re.MatchString(fi.Name()) {
-				flist = append(flist, fi.Name())
+			//the active file of an earlier day counts as rotated
+			earlier := cfg.daily && fi.Name() == cfg.name && filepath.Dir(path) != cfg.dir
+			if re.MatchString(fi.Name()) || earlier {
+				flist = append(flist, oldFile{path, fi})
 			}
 
 			return nil
 		})
 
-		if t.MaxFileCount <= 0 {
-			return
-		}
-
-		if int64(len(flist))+1 <= t.MaxFileCount {
-			return
+		if maxAge := t.GetMaxFileAge(); maxAge > 0 {
+			deadline := t._now().Add(-maxAge)
+			kept := flist[:0]
+			for _, fi := range flist {
+				if fi.ModTime().Before(deadline) {
+					t._reportError(os.Remove(fi.path))
+					continue
+				}
+				kept = append(kept, fi)
+			}
+			flist = kept
 		}
 
+		//oldest first. names alone don't order {seq} patterns correctly
 		sort.Slice(flist, func(i, j int) bool {
-			return flist[i] < flist[j]
+			if !flist[i].ModTime().Equal(flist[j].ModTime()) {
+				return flist[i].ModTime().Before(flist[j].ModTime())
+			}
+			return flist[i].path < flist[j].path
 		})
 
-		for i := 0; i < len(flist)+1-int(t.MaxFileCount); i++ {
-			os.Remove(filepath.Join(t.SaveDir, flist[i]))
+		if cfg.maxCount > 0 && int64(len(flist))+1 > cfg.maxCount {
+			n := len(flist) + 1 - int(cfg.maxCount)
+			for i := 0; i < n; i++ {
+				t._reportError(os.Remove(flist[i].path))
+			}
+			flist = flist[n:]
 		}
-	}
 
-	go func() {
-		for {
-			<-ch
-			cleanFile()
+		if maxTotal := t.GetMaxTotalSize(); maxTotal > 0 {
+			var total int64
+			if fi, err := os.Stat(filepath.Join(cfg.dir, cfg.name)); err == nil {
+				total = fi.Size()
+			}
+			for _, fi := range flist {
+				total += fi.Size()
+			}
+
+			for len(flist) > 0 && total > maxTotal {
+				t._reportError(os.Remove(flist[0].path))
+				total -= flist[0].Size()
+				flist = flist[1:]
+			}
 		}
-	}()
 
-	t.nofityDelFile = func() {
-		if len(ch) == 0 {
-			ch <- 1
+		if cfg.daily {
+			_removeEmptyDays(cfg)
 		}
+
+		t._reportError(t._updateManifest(nil))
 	}
+
+	t.nofityDelFile, _ = t._worker(cleanFile, t.compressDone)
 }
 
-func (t *EasyLog) _rename() {
-	oldpath := filepath.Join(t.SaveDir, t.FileName)
-	newname := fmt.Sprintf("%s.%s", t.FileName, time.Now().Format("20060102150405"))
-	newpath := filepath.Join(t.SaveDir, newname)
+//rename the current log file to its archived name and return the new path.
+//an empty string is returned if the rename fails.
+func (t *EasyLog) _rename(cfg fileConfig, tm time.Time) string {
+	//later writes must go to a new file, and windows can't rename it open
+	t._writeFooter(cfg)
+	t._closeFile()
 
-	for i := 0; i < 2; i++ {
-		if err := os.Rename(oldpath, newpath); err == nil {
-			return
-		}
-		time.Sleep(time.Second)
+	oldpath := filepath.Join(cfg.dir, cfg.name)
+	newpath := filepath.Join(cfg.dir, _rotatedName(cfg, tm))
+
+	err := t.fs.Rename(oldpath, newpath)
+	if err == nil {
+		t.renameFails = 0
+		return newpath
 	}
 
+	//windows refuses to rename a file another process holds open. keep
+	//appending to it and try again on a later flush instead of stalling
+	//the writer here.
+	t.renameFails++
+	if t.renameFails < 3 {
+		t.renameRetry = t._now().Add(time.Second)
+		t._reportError(fmt.Errorf("easylog: rotate %s: %w, retrying", oldpath, err))
+		return ""
+	}
+	t.renameFails = 0
+
+	//still held open. copy the content aside and truncate in place so the
+	//size limit holds.
+	if cerr := _copyTruncate(t.fs, oldpath, newpath); cerr != nil {
+		t._reportError(fmt.Errorf("easylog: rotate %s: rename failed (%v) and copy-truncate failed: %w", oldpath, err, cerr))
+		return ""
+	}
+
+	t._reportError(fmt.Errorf("easylog: rotate %s: rename failed, rotated by copy-truncate: %w", oldpath, err))
+
+	return newpath
 }
 
-func (t *EasyLog) _tryWrite(data *bytes.Buffer) bool {
-	fullPath := filepath.Join(t.SaveDir, t.FileName)
-	f, err := os.OpenFile(fullPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, os.ModePerm|os.ModeTemporary)
-	if err != nil {
-		return true
+//post-process a rotated file, then trigger cleanup
+func (t *EasyLog) _afterRotate(path string) {
+	if path != "" {
+		atomic.AddUint64(&t.counters.rotations, 1)
+		t._rotateShadow()
 	}
 
-	defer f.Close()
+	f := rotatedFile{path, t.fileFirst, t.fileLast}
+	if path != "" {
+		t.fileFirst, t.fileLast = time.Time{}, time.Time{}
+	}
 
-	info, _ := f.Stat()
-	fsize := info.Size()
-	if fsize+int64(data.Len()) > t.MaxFileSize {
-		return false
+	if path != "" && (t.GetCompressRotated() || t._archiveConfig().archiver != nil || t.GetManifest()) {
+		t._queueRotated(f)
+		return
 	}
 
-	io.Copy(f, data)
+	t.nofityDelFile()
+}
+
+func (t *EasyLog) _writeFile(data *bytes.Buffer) error {
+	cfg := t._fileConfig()
+	if cfg.fallbackDir != "" {
+		return t._writeWithFallback(cfg, data)
+	}
 
-	return true
+	return t._writePrimary(cfg, data)
 }
 
-func (t *EasyLog) _mustWrite(data *bytes.Buffer) {
-	fullPath := filepath.Join(t.SaveDir, t.FileName)
-	f, err := os.OpenFile(fullPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, os.ModePerm|os.ModeTemporary)
+func (t *EasyLog) _writePrimary(cfg fileConfig, data *bytes.Buffer) error {
+	unlock, err := t._lock(cfg)
 	if err != nil {
-		return
+		return err
 	}
+	defer unlock()
 
-	defer f.Close()
+	t._recover(cfg)
+	t._checkPeriod(cfg)
 
-	io.Copy(f, data)
+	//what doesn't fit into the current file goes to the next one, whole
+	//entries at a time, so files stay within the max size
+	for data.Len() > 0 {
+		f, err := t._openFile(cfg)
+		if err != nil {
+			return err
+		}
+		if err := t._writeHeader(f); err != nil {
+			return err
+		}
 
-	return
+		n := t._fit(data.Bytes(), cfg.maxSize-t.fileSize)
+		//a failed rename is retried later, until then the file grows
+		if n == 0 && t._now().Before(t.renameRetry) {
+			n = data.Len()
+		}
+
+		//a streamed entry goes to one file, rotated before it if needed
+		if t.streaming {
+			n = data.Len()
+			if !t.streamStarted && t.fileSize > t.headerSize && t.fileSize+t.streamSize > cfg.maxSize {
+				n = 0
+			}
+			t.streamStarted = true
+		}
+
+		if n == 0 {
+			t._afterRotate(t._rename(cfg, t._now()))
+			continue
+		}
+
+		p := data.Bytes()[:n]
+		if !t._transcodeEarly() {
+			p = t._transcode(p)
+		}
+		if err := t._writeOpen(f, p); err != nil {
+			return err
+		}
+		t._markWrite()
+		data.Next(n)
+	}
+
+	return nil
 }
 
-func (t *EasyLog) _writeFile(data *bytes.Buffer) {
-	if t._tryWrite(data) {
-		return
+//number of leading bytes of p to write into a file with room bytes left,
+//ending at an entry boundary. a file with nothing but its header takes at
+//least one entry, even if it is too large. encrypted batches are never split.
+func (t *EasyLog) _fit(p []byte, room int64) int {
+	if int64(len(p)) <= room {
+		return len(p)
 	}
 
-	t._rename()
-	t._mustWrite(data)
-	t.nofityDelFile()
+	box, _ := t.crypt.Load().(aeadBox)
+	if box.aead != nil {
+		if t.fileSize <= t.headerSize {
+			return len(p)
+		}
+		return 0
+	}
 
-	return
+	if room > 0 {
+		if i := bytes.LastIndexByte(p[:room], t._sepByte()); i >= 0 {
+			return i + 1
+		}
+	}
+
+	if t.fileSize <= t.headerSize {
+		if i := bytes.IndexByte(p, t._sepByte()); i >= 0 {
+			return i + 1
+		}
+		return len(p)
+	}
+
+	return 0
 }
 
-func (t *EasyLog) _serveLog() {
-	CalcMaxCacheSize := func() int {
-		nMax := int(t.MaxFileSize)
-		if nMax > 1024*1024*1 {
-			nMax = 1024 * 1024 * 1
+//add a queued entry to data and recycle its buffer
+func (t *EasyLog) _append(data *bytes.Buffer, v *bytes.Buffer) {
+	if t.GetAuditMode() {
+		n := data.Len() + v.Len()
+		t._auditRecord(data, v.Bytes())
+		t._grow(int64(data.Len() - n))
+	} else {
+		data.Write(v.Bytes())
+	}
+	t._putBuffer(v)
+}
+
+//move everything queued in the pipe into data without blocking
+func (t *EasyLog) _drain(data *bytes.Buffer) {
+	for {
+		select {
+		case v := <-t.pipe:
+			t._append(data, v)
+		default:
+			return
+		}
+	}
+}
+
+func (t *EasyLog) _flush(data *bytes.Buffer) error {
+	t.batchEntries = 0
+	if data.Len() == 0 && t.retryBuf.Len() == 0 {
+		return nil
+	}
+
+	defer t._release(int64(data.Len()))
+
+	n := data.Len()
+	var err error
+	if n > 0 {
+		t._writeOutputs(data)
+		t._keepRecent(data.Bytes())
+		if t._transcodeEarly() {
+			t._transcodeBuffer(data)
 		}
-		return nMax
+		err = t._encrypt(data)
+	}
+	if err == nil {
+		err = t._writeRetrying(data.Bytes())
+	}
+	data.Reset()
+	if err == nil {
+		atomic.AddUint64(&t.counters.bytes, uint64(n))
+		t._resetBreaker()
+		t.dirty = true
+		t._syncIfDue()
+	} else {
+		atomic.AddUint64(&t.counters.flushErrors, 1)
+	}
+	t.lastErr.Store(errorBox{err})
+	t._reportError(err)
+
+	return err
+}
+
+//flush data and fsync the active log file
+func (t *EasyLog) _flushSync(data *bytes.Buffer) error {
+	if err := t._flush(data); err != nil {
+		return err
 	}
 
-	do := func() {
+	if !t.dirty {
+		return nil
+	}
+
+	return t._fsync()
+}
+
+//files also expire while nothing is rotated, so the cleanup pass runs every
+//10 minutes while a max age is set. only called from the serve goroutine.
+func (t *EasyLog) _checkAge(now time.Time) {
+	if t.GetMaxFileAge() <= 0 || now.Sub(t.lastAgeCheck) < time.Minute*10 {
+		return
+	}
+
+	if !t.lastAgeCheck.IsZero() {
+		t.nofityDelFile()
+	}
+	t.lastAgeCheck = now
+}
+
+func (t *EasyLog) _serveLog() {
+	defer t.wg.Done()
+	defer close(t.serveDone)
+
+	data := &bytes.Buffer{}
+
+	do := func() (exit bool) {
 		defer func() {
-			recover()
+			if recover() != nil {
+				exit = false
+			}
 		}()
 
-		maxCacheSize := CalcMaxCacheSize()
-		data := &bytes.Buffer{}
+		maxCacheSize := t._batchLimit()
+
+		tm := t.clock.NewTicker(t.GetFlushFreq())
+		defer func() { tm.Stop() }()
+
+		//fires once the oldest entry of the batch waited for MaxBatchDelay
+		delay := t.clock.NewTimer(time.Hour)
+		delay.Stop()
+		defer delay.Stop()
+		waiting := false
 
-		tm := time.NewTicker(t.FlushFreq)
 		for {
+			empty := data.Len() == 0
+
 			select {
-			case v, ok := <-t.Pipe:
-				if ok {
-					data.Write(v.Bytes())
-					v.Reset()
-					t.pool.Put(v)
+			case v := <-t.pipe:
+				t._append(data, v)
+				t.batchEntries++
+			case now := <-tm.C():
+				t._checkFile(now)
+				t._recoverIdle()
+				t._flush(data)
+				t._syncIfDue()
+				maxCacheSize = t._batchLimit()
+				t._checkAge(now)
+			case <-delay.C():
+				waiting = false
+				t._flush(data)
+			case <-t.freqReq:
+				tm.Stop()
+				tm = t.clock.NewTicker(t.GetFlushFreq())
+			case ch := <-t.flushReq:
+				t._drain(data)
+				ch <- t._flush(data)
+			case cmd := <-t.cmdReq:
+				cmd.done <- cmd.fn(data)
+			case req := <-t.syncReq:
+				t._drain(data)
+				//the entry is already in memory, and _flush releases it
+				t._grow(int64(req.buf.Len()))
+				t._append(data, req.buf)
+				req.done <- t._flushSync(data)
+			case <-t.quit:
+				t._drain(data)
+				t.closeErr = t._flush(data)
+				if err := t._closeSink(); t.closeErr == nil {
+					t.closeErr = err
 				}
-			case <-tm.C:
-				if data.Len() > 0 {
-					t._writeFile(data)
-					data.Reset()
-				}
-				maxCacheSize = CalcMaxCacheSize()
+				return true
+			}
+
+			//a full batch is written right away, not at the next tick
+			if data.Len() >= maxCacheSize {
+				t._flush(data)
+				maxCacheSize = t._batchLimit()
+			} else if n := t.GetFlushEvery(); n > 0 && t.batchEntries >= n {
+				t._flush(data)
 			}
 
-			if data.Len() > maxCacheSize {
-				<-tm.C
-				t._writeFile(data)
-				data.Reset()
+			if d := t.GetMaxBatchDelay(); d > 0 && empty && data.Len() > 0 && !waiting {
+				delay.Reset(d)
+				waiting = true
+			} else if waiting && data.Len() == 0 {
+				if !delay.Stop() {
+					<-delay.C()
+				}
+				waiting = false
 			}
 		}
 	}
 
-	for {
-		do()
+	for !do() {
 	}
-
-	return
 }
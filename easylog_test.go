@@ -0,0 +1,97 @@
+package easylog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+//a logger writing to a temporary directory on the time of c
+func newTestLog(tb testing.TB, opts Options, c clock) *EasyLog {
+	tb.Helper()
+
+	if opts.Dir == "" {
+		opts.Dir = tb.TempDir()
+	}
+	if opts.FileName == "" {
+		opts.FileName = "test.log"
+	}
+
+	l, err := _newLogWithClock(opts, c)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { l.Close() })
+
+	return l
+}
+
+//wait until the serve goroutine took everything queued so far and handled
+//it, as it does between flushes
+func settle(tb testing.TB, l *EasyLog) {
+	tb.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for len(l.pipe) > 0 {
+		if time.Now().After(deadline) {
+			tb.Fatal("the queue is never taken")
+		}
+		runtime.Gosched()
+	}
+
+	//the last entry may still be handled, commands wait for it
+	if err := l._do(func(data *bytes.Buffer) error { return nil }); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func readLog(tb testing.TB, l *EasyLog) string {
+	tb.Helper()
+
+	dir, name := l.GetDir()
+	b, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil && !os.IsNotExist(err) {
+		tb.Fatal(err)
+	}
+
+	return string(b)
+}
+
+func TestFlushOnTick(t *testing.T) {
+	c := newFakeClock(time.Now())
+	l := newTestLog(t, Options{FlushFreq: time.Second}, c)
+
+	l.Write([]byte("first"))
+	settle(t, l)
+	c.Advance(time.Second - time.Millisecond)
+	settle(t, l)
+	if got := readLog(t, l); got != "" {
+		t.Fatalf("flushed before the tick: %q", got)
+	}
+
+	c.Advance(time.Millisecond)
+	settle(t, l)
+	if got := readLog(t, l); got != "first\n" {
+		t.Fatalf("after the tick the file holds %q, want %q", got, "first\n")
+	}
+}
+
+func TestMaxBatchDelay(t *testing.T) {
+	c := newFakeClock(time.Now())
+	l := newTestLog(t, Options{FlushFreq: time.Hour, MaxBatchDelay: 50 * time.Millisecond}, c)
+
+	l.Write([]byte("waiting"))
+	settle(t, l)
+	if got := readLog(t, l); got != "" {
+		t.Fatalf("flushed before the delay: %q", got)
+	}
+
+	c.Advance(50 * time.Millisecond)
+	settle(t, l)
+	if got := readLog(t, l); got != "waiting\n" {
+		t.Fatalf("after the delay the file holds %q, want %q", got, "waiting\n")
+	}
+}
@@ -0,0 +1,89 @@
+package easylog
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+//Encoder turns a leveled entry into the bytes stored for it. Encode appends
+//one record to buf, a missing trailing newline is added and replaced by the
+//record separator. writing nothing drops the entry, so an Encoder wrapping
+//another one can filter entries as well as change them.
+//entries are encoded on the logging goroutine, and an error is reported
+//through OnError and drops the entry.
+type Encoder interface {
+	Encode(buf *bytes.Buffer, e *Entry) error
+}
+
+type encoderBox struct {
+	enc Encoder
+}
+
+//encode leveled entries with enc instead of the format set by SetFormat.
+//Write is not affected. if enc == nil, the format is used again.
+func (t *EasyLog) SetEncoder(enc Encoder) error {
+	t.encoder.Store(encoderBox{enc})
+	return nil
+}
+
+//return the Encoder of leveled entries. unless SetEncoder was used, it is
+//the one of the current format, with the time format, prefix and CSV columns
+//set at the time of the call. wrap it to extend the built-in formats.
+func (t *EasyLog) GetEncoder() Encoder {
+	box, _ := t.encoder.Load().(encoderBox)
+	if box.enc != nil {
+		return box.enc
+	}
+
+	cfg := t._encodeConfig()
+	switch t.GetFormat() {
+	case JSONFormat:
+		return jsonEncoder{cfg}
+	case LogfmtFormat:
+		return logfmtEncoder{cfg}
+	case CSVFormat:
+		return csvEncoder{cfg}
+	}
+
+	return textEncoder{cfg}
+}
+
+//report whether SetEncoder replaced the format's encoder
+func (t *EasyLog) _customEncoder() bool {
+	box, _ := t.encoder.Load().(encoderBox)
+	return box.enc != nil
+}
+
+//encode an entry into buf, ending with the record separator. it returns
+//false if the entry was dropped.
+func (t *EasyLog) _encode(buf *bytes.Buffer, now time.Time, level Level, msg string, fields Fields) bool {
+	start := buf.Len()
+	e := Entry{Time: now, Level: level, Message: msg, Fields: fields}
+	if err := _safeEncode(t.GetEncoder(), buf, &e); err != nil {
+		t._reportError(err)
+		buf.Truncate(start)
+		return false
+	}
+	if buf.Len() == start {
+		return false
+	}
+
+	if buf.Bytes()[buf.Len()-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	t._terminate(buf)
+
+	return true
+}
+
+//run a possibly user supplied encoder, turning a panic into an error
+func _safeEncode(enc Encoder, buf *bytes.Buffer, e *Entry) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("easylog: encoder panic: %v", r)
+		}
+	}()
+
+	return enc.Encode(buf, e)
+}
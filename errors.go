@@ -0,0 +1,51 @@
+package easylog
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+var ErrClosed = errors.New("easylog: logger is closed")
+
+type errorHandler func(error)
+
+type errorBox struct {
+	err error
+}
+
+//return the error of the last flush if it failed to write its batch to the
+//file or sink, nil once a later flush succeeds. Write and WriteString return
+//it too, since entries queued earlier may not be stored. the entries are
+//kept for a retry up to SetRetryBuffer's limit. with SetShards, it is the
+//error of the first shard whose last flush failed.
+func (t *EasyLog) LastError() error {
+	box, _ := t.lastErr.Load().(errorBox)
+	if box.err == nil {
+		for _, child := range t._shardLogs() {
+			if err := child.LastError(); err != nil {
+				return err
+			}
+		}
+	}
+	return box.err
+}
+
+//set a callback that receives errors from the background goroutines, such as
+//failures to open, write, rename, compress or delete log files.
+//the callback is invoked from the goroutine that hit the error, so it should
+//return quickly. pass nil to remove the callback.
+func (t *EasyLog) OnError(fn func(error)) {
+	t.onError.Store(errorHandler(fn))
+}
+
+func (t *EasyLog) _reportError(err error) {
+	if err == nil {
+		return
+	}
+
+	atomic.AddUint64(&t.counters.errors, 1)
+
+	if fn, _ := t.onError.Load().(errorHandler); fn != nil {
+		fn(err)
+	}
+}
@@ -0,0 +1,107 @@
+package easylog
+
+import (
+	"bytes"
+	"fmt"
+)
+
+//EscapeMode selects how control characters in TextFormat messages and field
+//values are written. the other formats quote them already.
+type EscapeMode int32
+
+const (
+	//write messages as they are. this is the default.
+	EscapeOff EscapeMode = iota
+	//write newlines, tabs and other control characters as \n, \t, \x1b and
+	//so on, so every entry stays on one line for line oriented parsers
+	EscapeAll
+	//keep line breaks for humans, but indent the following lines with a tab,
+	//so they can't be taken for new entries. other control characters are
+	//escaped.
+	EscapeIndent
+)
+
+func (m EscapeMode) String() string {
+	switch m {
+	case EscapeOff:
+		return "Off"
+	case EscapeAll:
+		return "All"
+	case EscapeIndent:
+		return "Indent"
+	}
+
+	return fmt.Sprintf("EscapeMode(%d)", int32(m))
+}
+
+//set how control characters in TextFormat entries are written
+func (t *EasyLog) SetEscapeMode(mode EscapeMode) error {
+	if mode < EscapeOff || mode > EscapeIndent {
+		return fmt.Errorf("easylog: invalid escape mode %d", int32(mode))
+	}
+
+	t.encMu.Lock()
+	defer t.encMu.Unlock()
+
+	cfg := t._encodeConfig()
+	cfg.escape = mode
+	t.encCfg.Store(cfg)
+
+	return nil
+}
+
+func (t *EasyLog) GetEscapeMode() EscapeMode {
+	return t._encodeConfig().escape
+}
+
+func _hasControl(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < ' ' || s[i] == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+func _writeEscaped(buf *bytes.Buffer, s string, mode EscapeMode) {
+	if mode == EscapeOff || !_hasControl(s) {
+		buf.WriteString(s)
+		return
+	}
+
+	const hex = "0123456789abcdef"
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\n' && mode == EscapeIndent:
+			buf.WriteString("\n\t")
+		case c == '\r' && mode == EscapeIndent && i+1 < len(s) && s[i+1] == '\n':
+		case c == '\t' && mode == EscapeIndent:
+			buf.WriteByte(c)
+		case c == '\n':
+			buf.WriteString(`\n`)
+		case c == '\r':
+			buf.WriteString(`\r`)
+		case c == '\t':
+			buf.WriteString(`\t`)
+		case c < ' ' || c == 0x7f:
+			buf.WriteString(`\x`)
+			buf.WriteByte(hex[c>>4])
+			buf.WriteByte(hex[c&0xf])
+		default:
+			buf.WriteByte(c)
+		}
+	}
+}
+
+//escape the message formatted into buf from start
+func _escapeTail(buf *bytes.Buffer, start int, mode EscapeMode) {
+	msg := buf.Bytes()[start:]
+	if mode == EscapeOff || bytes.IndexFunc(msg, func(r rune) bool { return r < ' ' || r == 0x7f }) < 0 {
+		return
+	}
+
+	s := string(msg)
+	buf.Truncate(start)
+	_writeEscaped(buf, s, mode)
+}
@@ -0,0 +1,120 @@
+package easylog
+
+import (
+	"fmt"
+	"os"
+)
+
+type exitFunc func(code int)
+
+//set the function called by Fatal, Fatalf and Fatalln after the entry is
+//flushed. tests can replace it to record the exit code instead of exiting.
+//if fn == nil, os.Exit is used. this is the default.
+func (t *EasyLog) SetExitFunc(fn func(code int)) error {
+	t.exitFn.Store(exitFunc(fn))
+	return nil
+}
+
+//log at FatalLevel, flush, then exit with status 1
+func (t *EasyLog) Fatal(args ...interface{}) {
+	t._logs(FatalLevel, nil, fmt.Sprint(args...))
+	t._fatal()
+}
+
+func (t *EasyLog) Fatalf(format string, args ...interface{}) {
+	t._logs(FatalLevel, nil, fmt.Sprintf(format, args...))
+	t._fatal()
+}
+
+func (t *EasyLog) Fatalln(args ...interface{}) {
+	t._logs(FatalLevel, nil, fmt.Sprintln(args...))
+	t._fatal()
+}
+
+//log at PanicLevel, flush, then panic with the message
+func (t *EasyLog) Panic(args ...interface{}) {
+	msg := fmt.Sprint(args...)
+	t._logs(PanicLevel, nil, msg)
+	t._panic(msg)
+}
+
+func (t *EasyLog) Panicf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	t._logs(PanicLevel, nil, msg)
+	t._panic(msg)
+}
+
+func (t *EasyLog) Panicln(args ...interface{}) {
+	msg := fmt.Sprintln(args...)
+	t._logs(PanicLevel, nil, msg)
+	t._panic(msg)
+}
+
+func (l *Logger) Fatal(args ...interface{}) {
+	log, k := l._acquire()
+	log._logs(FatalLevel, l.fields, fmt.Sprint(args...))
+	log._fatal()
+	l._release(k)
+}
+
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	log, k := l._acquire()
+	log._logs(FatalLevel, l.fields, fmt.Sprintf(format, args...))
+	log._fatal()
+	l._release(k)
+}
+
+func (l *Logger) Fatalln(args ...interface{}) {
+	log, k := l._acquire()
+	log._logs(FatalLevel, l.fields, fmt.Sprintln(args...))
+	log._fatal()
+	l._release(k)
+}
+
+func (l *Logger) Panic(args ...interface{}) {
+	msg := fmt.Sprint(args...)
+	log, k := l._acquire()
+	defer l._release(k)
+	log._logs(PanicLevel, l.fields, msg)
+	log._panic(msg)
+}
+
+func (l *Logger) Panicf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	log, k := l._acquire()
+	defer l._release(k)
+	log._logs(PanicLevel, l.fields, msg)
+	log._panic(msg)
+}
+
+func (l *Logger) Panicln(args ...interface{}) {
+	msg := fmt.Sprintln(args...)
+	log, k := l._acquire()
+	defer l._release(k)
+	log._logs(PanicLevel, l.fields, msg)
+	log._panic(msg)
+}
+
+func (t *EasyLog) _fatal() {
+	t._flushLevel(FatalLevel)
+
+	fn, _ := t.exitFn.Load().(exitFunc)
+	if fn == nil {
+		fn = os.Exit
+	}
+	fn(1)
+}
+
+func (t *EasyLog) _panic(msg string) {
+	t._flushLevel(PanicLevel)
+	panic(msg)
+}
+
+//write an entry of level to disk before the program stops, here and in
+//the loggers it is routed to
+func (t *EasyLog) _flushLevel(level Level) {
+	t.Flush()
+	for _, target := range t._routesFor(level) {
+		target.Flush()
+	}
+}
@@ -0,0 +1,270 @@
+package easylog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+type Format int32
+
+const (
+	TextFormat Format = iota
+	JSONFormat
+	LogfmtFormat
+	CSVFormat
+)
+
+func (f Format) String() string {
+	switch f {
+	case TextFormat:
+		return "text"
+	case JSONFormat:
+		return "json"
+	case LogfmtFormat:
+		return "logfmt"
+	case CSVFormat:
+		return "csv"
+	}
+
+	return fmt.Sprintf("Format(%d)", int32(f))
+}
+
+//key/value pairs attached to a log entry
+type Fields map[string]interface{}
+
+//set the output format of leveled entries. Write is not affected and always
+//stores raw bytes.
+func (t *EasyLog) SetFormat(format Format) error {
+	if format < TextFormat || format > CSVFormat {
+		return fmt.Errorf("easylog: invalid format %d", int32(format))
+	}
+
+	atomic.StoreInt32(&t.format, int32(format))
+
+	return nil
+}
+
+func (t *EasyLog) GetFormat() Format {
+	return Format(atomic.LoadInt32(&t.format))
+}
+
+//layout and prefix applied to every leveled entry
+type encodeConfig struct {
+	timeFormat string
+	prefix     string
+	csvColumns []string
+	escape     EscapeMode
+}
+
+//set the time layout of leveled entries, as accepted by time.Format.
+//an empty layout restores the default: "2006-01-02 15:04:05.000" for
+//TextFormat and time.RFC3339Nano for JSONFormat.
+func (t *EasyLog) SetTimeFormat(layout string) error {
+	t.encMu.Lock()
+	defer t.encMu.Unlock()
+
+	cfg := t._encodeConfig()
+	cfg.timeFormat = layout
+	t.encCfg.Store(cfg)
+
+	return nil
+}
+
+//set a prefix, typically the application name, written at the beginning of
+//every text entry and as the "prefix" key of every JSON entry.
+func (t *EasyLog) SetPrefix(prefix string) error {
+	t.encMu.Lock()
+	defer t.encMu.Unlock()
+
+	cfg := t._encodeConfig()
+	cfg.prefix = prefix
+	t.encCfg.Store(cfg)
+
+	return nil
+}
+
+func (t *EasyLog) _encodeConfig() encodeConfig {
+	cfg, _ := t.encCfg.Load().(encodeConfig)
+	return cfg
+}
+
+//"<time> [LEVEL] message key=value ..."
+type textEncoder struct {
+	cfg encodeConfig
+}
+
+func (enc textEncoder) Encode(buf *bytes.Buffer, e *Entry) error {
+	cfg := enc.cfg
+	layout := cfg.timeFormat
+	if layout == "" {
+		layout = "2006-01-02 15:04:05.000"
+	}
+
+	buf.WriteString(cfg.prefix)
+	_writeTime(buf, e.Time, layout)
+	buf.WriteString(" [")
+	buf.WriteString(e.Level.String())
+	buf.WriteString("] ")
+	_writeEscaped(buf, strings.TrimRight(e.Message, "\n"), cfg.escape)
+
+	for _, k := range _sortedKeys(e.Fields) {
+		buf.WriteByte(' ')
+		buf.WriteString(k)
+		buf.WriteByte('=')
+		if cfg.escape == EscapeOff {
+			fmt.Fprint(buf, _fieldValue(e.Fields[k]))
+		} else {
+			_writeEscaped(buf, fmt.Sprint(_fieldValue(e.Fields[k])), cfg.escape)
+		}
+	}
+
+	buf.WriteByte('\n')
+
+	return nil
+}
+
+//write now in layout without allocating a string
+func _writeTime(buf *bytes.Buffer, now time.Time, layout string) {
+	var scratch [64]byte
+	buf.Write(now.AppendFormat(scratch[:0], layout))
+}
+
+type jsonEncoder struct {
+	cfg encodeConfig
+}
+
+func (enc jsonEncoder) Encode(buf *bytes.Buffer, e *Entry) error {
+	cfg := enc.cfg
+	layout := cfg.timeFormat
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+
+	buf.WriteString(`{"time":`)
+	_writeJSON(buf, e.Time.Format(layout))
+	if prefix := strings.TrimSpace(cfg.prefix); prefix != "" {
+		buf.WriteString(`,"prefix":`)
+		_writeJSON(buf, prefix)
+	}
+	buf.WriteString(`,"level":`)
+	_writeJSON(buf, strings.ToLower(e.Level.String()))
+	buf.WriteString(`,"msg":`)
+	_writeJSON(buf, strings.TrimRight(e.Message, "\n"))
+
+	for _, k := range _sortedKeys(e.Fields) {
+		key := k
+		if key == "time" || key == "level" || key == "msg" || key == "prefix" {
+			//don't let user fields clobber the reserved keys
+			key = "fields." + key
+		}
+		buf.WriteByte(',')
+		_writeJSON(buf, key)
+		buf.WriteByte(':')
+		_writeJSON(buf, _fieldValue(e.Fields[k]))
+	}
+
+	buf.WriteString("}\n")
+
+	return nil
+}
+
+//key=value pairs as understood by logfmt parsers such as Grafana Loki's
+type logfmtEncoder struct {
+	cfg encodeConfig
+}
+
+func (enc logfmtEncoder) Encode(buf *bytes.Buffer, e *Entry) error {
+	cfg := enc.cfg
+	layout := cfg.timeFormat
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+
+	buf.WriteString("time=")
+	_writeLogfmtValue(buf, e.Time.Format(layout))
+	if prefix := strings.TrimSpace(cfg.prefix); prefix != "" {
+		buf.WriteString(" prefix=")
+		_writeLogfmtValue(buf, prefix)
+	}
+	buf.WriteString(" level=")
+	buf.WriteString(strings.ToLower(e.Level.String()))
+	buf.WriteString(" msg=")
+	_writeLogfmtValue(buf, strings.TrimRight(e.Message, "\n"))
+
+	for _, k := range _sortedKeys(e.Fields) {
+		key := k
+		if key == "time" || key == "level" || key == "msg" || key == "prefix" {
+			key = "fields." + key
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(_logfmtKey(key))
+		buf.WriteByte('=')
+		_writeLogfmtValue(buf, fmt.Sprint(_fieldValue(e.Fields[k])))
+	}
+
+	buf.WriteByte('\n')
+
+	return nil
+}
+
+//logfmt keys can't contain spaces, '=' or quotes
+func _logfmtKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+func _writeLogfmtValue(buf *bytes.Buffer, v string) {
+	if v == "" {
+		buf.WriteString(`""`)
+		return
+	}
+
+	if strings.IndexFunc(v, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError
+	}) >= 0 {
+		buf.WriteString(strconv.Quote(v))
+		return
+	}
+
+	buf.WriteString(v)
+}
+
+func _writeJSON(buf *bytes.Buffer, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(b)
+}
+
+func _fieldValue(v interface{}) interface{} {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+	return v
+}
+
+func _sortedKeys(fields Fields) []string {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
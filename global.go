@@ -0,0 +1,88 @@
+package easylog
+
+import (
+	"os"
+	"sync/atomic"
+)
+
+//set fields attached to every leveled entry, such as the app name, version
+//or region. fields of the entry itself take precedence. pass nil to remove
+//them.
+func (t *EasyLog) SetGlobalFields(fields map[string]string) error {
+	t.globalMu.Lock()
+	defer t.globalMu.Unlock()
+
+	t.userGlobals = make(Fields, len(fields))
+	for k, v := range fields {
+		t.userGlobals[k] = v
+	}
+	t._storeGlobals()
+
+	return nil
+}
+
+//attach the "host" and "pid" fields to every leveled entry, so aggregated
+//logs of many hosts and processes can be told apart
+func (t *EasyLog) SetHostFields(enable bool) error {
+	t.globalMu.Lock()
+	defer t.globalMu.Unlock()
+
+	var v int32
+	if enable {
+		v = 1
+	}
+	atomic.StoreInt32(&t.hostFields, v)
+	t._storeGlobals()
+
+	return nil
+}
+
+func (t *EasyLog) GetHostFields() bool {
+	return atomic.LoadInt32(&t.hostFields) == 1
+}
+
+//a copy of the fields set by SetGlobalFields, for child loggers
+func (t *EasyLog) _globalFields() map[string]string {
+	t.globalMu.Lock()
+	defer t.globalMu.Unlock()
+
+	fields := make(map[string]string, len(t.userGlobals))
+	for k, v := range t.userGlobals {
+		fields[k], _ = v.(string)
+	}
+
+	return fields
+}
+
+//combine user and host fields into the map read by _withGlobals
+func (t *EasyLog) _storeGlobals() {
+	globals := make(Fields, len(t.userGlobals)+2)
+	if atomic.LoadInt32(&t.hostFields) == 1 {
+		host, _ := os.Hostname()
+		globals["host"] = host
+		globals["pid"] = os.Getpid()
+	}
+	for k, v := range t.userGlobals {
+		globals[k] = v
+	}
+
+	t.globals.Store(globals)
+}
+
+//return fields plus the global fields. fields is not modified.
+func (t *EasyLog) _withGlobals(fields Fields) Fields {
+	globals, _ := t.globals.Load().(Fields)
+	if len(globals) == 0 {
+		return fields
+	}
+
+	merged := make(Fields, len(globals)+len(fields))
+	for k, v := range globals {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return merged
+}
@@ -1,3 +1,5 @@
 module github.com/carr123/easylog
 
 go 1.17
+
+require github.com/go-logr/logr v1.2.4
@@ -0,0 +1,93 @@
+package easylog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+type frameFunc func() []byte
+
+//set a function whose output starts every new log file, e.g. the app version,
+//the start time or column names. it is called on the serve goroutine when
+//entries are about to be written to an empty file. a missing record separator
+//is added. if fn == nil, files get no header, except that CSVFormat files
+//start with the column names.
+func (t *EasyLog) SetFileHeader(fn func() []byte) error {
+	t.fileHeader.Store(frameFunc(fn))
+	return nil
+}
+
+//set a function whose output ends every finished log file. it is written
+//before the file is rotated, or left behind because the file's path changed,
+//e.g. at a new day with daily directories. the active file gets no footer on
+//Close, since a later run may continue it. if fn == nil, files get no footer.
+func (t *EasyLog) SetFileFooter(fn func() []byte) error {
+	t.fileFooter.Store(frameFunc(fn))
+	return nil
+}
+
+//write the header to a file that is still empty.
+//only called from the serve goroutine.
+func (t *EasyLog) _writeHeader(f *os.File) error {
+	fn, _ := t.fileHeader.Load().(frameFunc)
+	if fn == nil && t.GetFormat() == CSVFormat {
+		fn = t._csvHeader
+	}
+	if fn == nil || t.fileSize > 0 {
+		return nil
+	}
+
+	if err := t._writeFrame(f, fn); err != nil {
+		return fmt.Errorf("easylog: write header: %w", err)
+	}
+	t.headerSize = t.fileSize
+
+	return nil
+}
+
+//write the footer to the file at cfg's path before it is rotated.
+//only called from the serve goroutine.
+func (t *EasyLog) _writeFooter(cfg fileConfig) {
+	if fn, _ := t.fileFooter.Load().(frameFunc); fn == nil {
+		return
+	}
+
+	f, err := t._openFile(cfg)
+	if err != nil {
+		t._reportError(fmt.Errorf("easylog: write footer: %w", err))
+		return
+	}
+	t._footer(f)
+}
+
+//write the footer to f, which is about to be closed for good
+func (t *EasyLog) _footer(f *os.File) {
+	fn, _ := t.fileFooter.Load().(frameFunc)
+	if fn == nil {
+		return
+	}
+
+	if err := t._writeFrame(f, fn); err != nil {
+		t._reportError(fmt.Errorf("easylog: write footer: %w", err))
+	}
+}
+
+//write fn's output to f as one record, encrypted like the entries
+func (t *EasyLog) _writeFrame(f *os.File, fn frameFunc) error {
+	var buf bytes.Buffer
+	buf.Write(fn())
+	if buf.Len() == 0 {
+		return nil
+	}
+	if !bytes.HasSuffix(buf.Bytes(), []byte(t.GetRecordSeparator())) {
+		buf.WriteString(t.GetRecordSeparator())
+	}
+	t._transcodeBuffer(&buf)
+
+	if err := t._encrypt(&buf); err != nil {
+		return err
+	}
+
+	return t._writeOpen(f, buf.Bytes())
+}
@@ -0,0 +1,83 @@
+package easylog
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+//Entry is a leveled log entry before it is encoded
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  Fields
+}
+
+//Hook is called for every leveled entry before it is queued. it may modify
+//the entry, e.g. to redact or enrich fields. returning ErrSkipEntry drops the
+//entry; any other error is reported through OnError and the entry is kept.
+type Hook func(entry *Entry) error
+
+var ErrSkipEntry = errors.New("easylog: skip entry")
+
+//add a hook run for every leveled entry, in the order hooks were added.
+//hooks run on the logging goroutine, so they see entries before the level
+//routes and the sampler do.
+func (t *EasyLog) AddHook(hook Hook) error {
+	if hook == nil {
+		return nil
+	}
+
+	t.hookMu.Lock()
+	defer t.hookMu.Unlock()
+
+	hooks := t._hooks()
+	updated := make([]Hook, 0, len(hooks)+1)
+	updated = append(updated, hooks...)
+	t.hookList.Store(append(updated, hook))
+
+	return nil
+}
+
+//remove all hooks
+func (t *EasyLog) ClearHooks() {
+	t.hookMu.Lock()
+	t.hookList.Store([]Hook(nil))
+	t.hookMu.Unlock()
+}
+
+func (t *EasyLog) _hooks() []Hook {
+	hooks, _ := t.hookList.Load().([]Hook)
+	return hooks
+}
+
+//run hooks on e. it returns false if the entry was vetoed.
+func (t *EasyLog) _runHooks(hooks []Hook, e *Entry) (keep bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			t._reportError(fmt.Errorf("easylog: hook panic: %v", r))
+			keep = true
+		}
+	}()
+
+	for _, hook := range hooks {
+		if err := hook(e); err != nil {
+			if err == ErrSkipEntry {
+				return false
+			}
+			t._reportError(err)
+		}
+	}
+
+	return true
+}
+
+func _copyFields(fields Fields) Fields {
+	c := make(Fields, len(fields))
+	for k, v := range fields {
+		c[k] = v
+	}
+
+	return c
+}
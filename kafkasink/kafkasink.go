@@ -0,0 +1,128 @@
+//Package kafkasink publishes easylog batches to a Kafka topic.
+//
+//the package does not depend on a Kafka client. wrap the client you already
+//use (sarama, kafka-go, confluent-kafka-go) in a Producer and add the sink to
+//a logger:
+//
+//  sink := kafkasink.New(producer, "app-logs")
+//  log.AddOutput(sink)
+//
+//the sink receives the same batches that EasyLog flushes to its file, so the
+//batching loop and flush frequency of the logger also apply to Kafka.
+//
+//to keep entries across restarts while Kafka is unreachable, put a spool in
+//front of the sink:
+//
+//  spool, err := easylog.NewSpool("/var/spool/app", sink)
+//  log.AddOutput(spool)
+package kafkasink
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+)
+
+//Message is a single record handed to the Producer
+type Message struct {
+	Topic string
+	Key   []byte
+	Value []byte
+	//partition to publish to, or -1 to let the producer's partitioner decide
+	Partition int32
+}
+
+//Producer is implemented by an adapter around a Kafka client. values are
+//copied out of the logger's buffers, so Produce may keep them after it
+//returns, e.g. for an async producer.
+type Producer interface {
+	Produce(msgs []Message) error
+}
+
+//KeyFunc returns the record key of a batch or line, nil for no key
+type KeyFunc func(value []byte) []byte
+
+//PartitionFunc returns the partition of a record, or -1
+type PartitionFunc func(key, value []byte) int32
+
+//Sink is an io.Writer that publishes everything written to it
+type Sink struct {
+	producer Producer
+	topic    string
+
+	mu        sync.Mutex
+	perLine   bool
+	key       KeyFunc
+	partition PartitionFunc
+}
+
+var ErrNoProducer = errors.New("kafkasink: nil producer")
+
+//create a sink publishing to topic through producer
+func New(producer Producer, topic string) *Sink {
+	return &Sink{producer: producer, topic: topic}
+}
+
+//publish one record per log line instead of one record per batch
+func (s *Sink) SetPerLine(perLine bool) {
+	s.mu.Lock()
+	s.perLine = perLine
+	s.mu.Unlock()
+}
+
+//set how record keys are derived. records have no key by default.
+func (s *Sink) SetKeyFunc(fn KeyFunc) {
+	s.mu.Lock()
+	s.key = fn
+	s.mu.Unlock()
+}
+
+//set how records are assigned to partitions. by default the producer
+//decides, usually by hashing the key.
+func (s *Sink) SetPartitionFunc(fn PartitionFunc) {
+	s.mu.Lock()
+	s.partition = fn
+	s.mu.Unlock()
+}
+
+func (s *Sink) Write(p []byte) (int, error) {
+	if s.producer == nil {
+		return 0, ErrNoProducer
+	}
+
+	s.mu.Lock()
+	perLine, keyFn, partFn := s.perLine, s.key, s.partition
+	s.mu.Unlock()
+
+	//the logger reuses p after Write returns
+	data := append([]byte(nil), p...)
+
+	var values [][]byte
+	if perLine {
+		for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+			if len(bytes.TrimRight(line, "\n")) > 0 {
+				values = append(values, bytes.TrimRight(line, "\n"))
+			}
+		}
+	} else {
+		values = [][]byte{data}
+	}
+
+	msgs := make([]Message, 0, len(values))
+	for _, v := range values {
+		msg := Message{Topic: s.topic, Value: v, Partition: -1}
+		if keyFn != nil {
+			msg.Key = keyFn(v)
+		}
+		if partFn != nil {
+			msg.Partition = partFn(msg.Key, v)
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if err := s.producer.Produce(msgs); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
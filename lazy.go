@@ -0,0 +1,36 @@
+package easylog
+
+import (
+	"os"
+)
+
+//delay creating the log directory until the first entry is written to it,
+//for services that may never log and tools that scan log directories.
+//SetDir and SetFallbackDir then don't touch the disk, so errors such as a
+//missing permission are reported by the first write, through OnError.
+//log files are created by their first write either way, so no empty file
+//is left behind by a logger that wrote nothing. it must be set before
+//SetDir to take effect there.
+func (t *EasyLog) SetLazyCreate(enable bool) error {
+	t.mu.Lock()
+	t.lazyCreate = enable
+	t.mu.Unlock()
+
+	return nil
+}
+
+func (t *EasyLog) GetLazyCreate() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.lazyCreate
+}
+
+//create the directory of the active file, unless SetDir already did
+func _makeDir(cfg fileConfig) error {
+	if !cfg.daily && !cfg.lazy {
+		return nil
+	}
+
+	return os.MkdirAll(cfg.dir, cfg.dirMode)
+}
@@ -0,0 +1,275 @@
+package easylog
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type Level int32
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+	//entries of Panic, Panicf and Panicln
+	PanicLevel
+	//entries of Fatal, Fatalf and Fatalln
+	FatalLevel
+
+	numLevels = int(FatalLevel) + 1
+)
+
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarnLevel:
+		return "WARN"
+	case ErrorLevel:
+		return "ERROR"
+	case PanicLevel:
+		return "PANIC"
+	case FatalLevel:
+		return "FATAL"
+	}
+
+	return fmt.Sprintf("LEVEL(%d)", int32(l))
+}
+
+//parse a level name such as "debug" or "WARN", ignoring case and spaces
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "DEBUG":
+		return DebugLevel, nil
+	case "INFO":
+		return InfoLevel, nil
+	case "WARN", "WARNING":
+		return WarnLevel, nil
+	case "ERROR":
+		return ErrorLevel, nil
+	case "PANIC":
+		return PanicLevel, nil
+	case "FATAL":
+		return FatalLevel, nil
+	}
+
+	return DebugLevel, fmt.Errorf("easylog: unknown level %q", s)
+}
+
+func (l Level) valid() bool {
+	return l >= DebugLevel && l <= FatalLevel
+}
+
+//set the minimum level to be logged. entries below this level are discarded.
+//it is safe to call SetLevel while logging from other goroutines.
+func (t *EasyLog) SetLevel(level Level) error {
+	if !level.valid() {
+		return fmt.Errorf("easylog: invalid level %d", int32(level))
+	}
+
+	atomic.StoreInt32(&t.level, int32(level))
+
+	return nil
+}
+
+//get the current minimum level
+func (t *EasyLog) GetLevel() Level {
+	return Level(atomic.LoadInt32(&t.level))
+}
+
+//report whether entries of the given level will be logged
+func (t *EasyLog) Enabled(level Level) bool {
+	return level >= t.GetLevel()
+}
+
+func (t *EasyLog) Debugf(format string, args ...interface{}) {
+	t._logf(DebugLevel, nil, format, args...)
+}
+
+func (t *EasyLog) Infof(format string, args ...interface{}) {
+	t._logf(InfoLevel, nil, format, args...)
+}
+
+func (t *EasyLog) Warnf(format string, args ...interface{}) {
+	t._logf(WarnLevel, nil, format, args...)
+}
+
+func (t *EasyLog) Errorf(format string, args ...interface{}) {
+	t._logf(ErrorLevel, nil, format, args...)
+}
+
+//log at InfoLevel. arguments are handled in the manner of fmt.Print
+func (t *EasyLog) Print(args ...interface{}) {
+	t._logs(InfoLevel, nil, fmt.Sprint(args...))
+}
+
+//log at InfoLevel. arguments are handled in the manner of fmt.Printf
+func (t *EasyLog) Printf(format string, args ...interface{}) {
+	t._logf(InfoLevel, nil, format, args...)
+}
+
+//log at InfoLevel. arguments are handled in the manner of fmt.Println
+func (t *EasyLog) Println(args ...interface{}) {
+	t._logs(InfoLevel, nil, fmt.Sprintln(args...))
+}
+
+func (t *EasyLog) _logs(level Level, fields Fields, msg string) {
+	if !t._wanted(level, fields) {
+		return
+	}
+
+	t._logMsg(t._now(), level, msg, t._withCaller(level, fields))
+}
+
+//log at level. arguments are handled in the manner of fmt.Printf
+func (t *EasyLog) Logf(level Level, format string, args ...interface{}) {
+	t._logf(level, nil, format, args...)
+}
+
+func (t *EasyLog) _logf(level Level, fields Fields, format string, args ...interface{}) {
+	if !t._wanted(level, fields) {
+		return
+	}
+
+	if fields == nil && t._plain() && !t._wantStack(level) {
+		t._logfPlain(t._now(), level, format, args...)
+		return
+	}
+
+	t._logMsg(t._now(), level, fmt.Sprintf(format, args...), t._withCaller(level, fields))
+}
+
+//report whether entries are just encoded as text and queued, with nothing
+//else that needs the message as a string
+func (t *EasyLog) _plain() bool {
+	routes, _ := t.routes.Load().([]levelRoute)
+	globals, _ := t.globals.Load().(Fields)
+
+	return t.GetFormat() == TextFormat && !t.GetReportCaller() &&
+		t._consoleMode() == consoleOff && len(t._hooks()) == 0 &&
+		t._sampler() == nil && t._deduper() == nil && len(routes) == 0 && len(globals) == 0 &&
+		len(t._levelCallbacks()) == 0 && t._shadow() == nil && !t._customEncoder()
+}
+
+//format the message straight into the pooled buffer, encoded like
+//textEncoder without fields
+func (t *EasyLog) _logfPlain(now time.Time, level Level, format string, args ...interface{}) {
+	atomic.AddUint64(&t.counters.entries[level], 1)
+
+	cfg := t._encodeConfig()
+	layout := cfg.timeFormat
+	if layout == "" {
+		layout = "2006-01-02 15:04:05.000"
+	}
+
+	now, unlock := t._stamp(now)
+	if unlock != nil {
+		defer unlock()
+	}
+
+	buf := t._getBuffer()
+	buf.WriteString(cfg.prefix)
+	_writeTime(buf, now, layout)
+	buf.WriteString(" [")
+	buf.WriteString(level.String())
+	buf.WriteString("] ")
+
+	start := buf.Len()
+	fmt.Fprintf(buf, format, args...)
+	end := buf.Len()
+	for end > start && buf.Bytes()[end-1] == '\n' {
+		end--
+	}
+	buf.Truncate(end)
+	t._truncateTail(buf, start)
+	_escapeTail(buf, start, cfg.escape)
+	buf.WriteString(t.GetRecordSeparator())
+
+	t._redact(buf)
+	t._shard(nil)._enqueue(buf)
+}
+
+//report whether an entry of level with fields goes anywhere, here or to a
+//level route
+func (t *EasyLog) _wanted(level Level, fields Fields) bool {
+	return level >= t._levelFor(fields) || len(t._routesFor(level)) > 0
+}
+
+//log an already formatted message here and to the matching level routes
+func (t *EasyLog) _logMsg(now time.Time, level Level, msg string, fields Fields) {
+	if hooks := t._hooks(); len(hooks) > 0 {
+		e := &Entry{Time: now, Level: level, Message: msg, Fields: _copyFields(fields)}
+		if !t._runHooks(hooks, e) {
+			return
+		}
+		if e.Level.valid() {
+			level = e.Level
+		}
+		now, msg, fields = e.Time, e.Message, e.Fields
+	}
+
+	if d := t._deduper(); d != nil && !d.allow(t, now, level, msg, fields) {
+		return
+	}
+
+	if s := t._sampler(); s != nil && !s.allow(t, now, level, msg) {
+		return
+	}
+
+	t._dispatch(now, level, msg, fields)
+}
+
+func (t *EasyLog) _dispatch(now time.Time, level Level, msg string, fields Fields) {
+	routes := t._routesFor(level)
+
+	t._log(now, level, msg, fields)
+	for _, target := range routes {
+		target._log(now, level, msg, fields)
+	}
+}
+
+//encode and enqueue a single entry, honoring this logger's level and the
+//level of the entry's module
+func (t *EasyLog) _log(now time.Time, level Level, msg string, fields Fields) {
+	if level < t._levelFor(fields) {
+		return
+	}
+
+	atomic.AddUint64(&t.counters.entries[level], 1)
+	fields = t._withGlobals(fields)
+	t._alert(Entry{Time: now, Level: level, Message: msg, Fields: fields})
+
+	if mode := t._consoleMode(); mode != consoleOff {
+		t._writeConsole(now, level, msg, fields)
+		if mode == consoleExclusive {
+			return
+		}
+	}
+
+	now, unlock := t._stamp(now)
+	buf := t._getBuffer()
+	if t._encode(buf, now, level, t._truncateMsg(msg), fields) {
+		t._redact(buf)
+		t._shard(fields)._enqueue(buf)
+	} else {
+		t._putBuffer(buf)
+	}
+	if unlock != nil {
+		unlock()
+	}
+
+	//the shadow copy is cut and scrubbed like the entry itself
+	if sh := t._shadow(); sh != nil {
+		buf := sh._getBuffer()
+		e := Entry{Time: now, Level: level, Message: t._truncateMsg(msg), Fields: fields}
+		jsonEncoder{}.Encode(buf, &e)
+		t._terminate(buf)
+		t._redact(buf)
+		sh._enqueue(buf)
+	}
+}
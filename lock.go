@@ -0,0 +1,93 @@
+package easylog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+var errLockUnsupported = errors.New("easylog: file locking is not supported on this platform")
+
+//share the log file with other processes. every write takes an advisory
+//lock on "<file name>.lock" in the log directory, checks the size other
+//processes wrote and appends under the lock, so entries don't interleave and
+//only one process rotates. it returns an error on platforms without flock.
+func (t *EasyLog) SetMultiProcess(enable bool) error {
+	var v int32
+	if enable {
+		if !t.fs.CanLock() {
+			return errLockUnsupported
+		}
+		v = 1
+	}
+
+	atomic.StoreInt32(&t.multiProcess, v)
+
+	return nil
+}
+
+func (t *EasyLog) GetMultiProcess() bool {
+	return atomic.LoadInt32(&t.multiProcess) == 1
+}
+
+//take the lock shared with other processes. the returned function releases
+//it. without multi-process mode it does nothing.
+func (t *EasyLog) _lock(cfg fileConfig) (unlock func(), err error) {
+	if !t.GetMultiProcess() {
+		return func() {}, nil
+	}
+
+	lockPath := filepath.Join(cfg.dir, cfg.name+".lock")
+	if t.lockFile == nil || t.lockFile.Name() != lockPath {
+		t._closeLock()
+
+		if err := _makeDir(cfg); err != nil {
+			return nil, err
+		}
+
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, cfg.fileMode)
+		if err != nil {
+			return nil, err
+		}
+		t.lockFile = f
+	}
+
+	f := t.lockFile
+	if err := t.fs.Lock(f); err != nil {
+		return nil, err
+	}
+
+	//another process may have written or rotated the file meanwhile
+	t._refreshFile(cfg)
+
+	return func() { t.fs.Unlock(f) }, nil
+}
+
+func (t *EasyLog) _closeLock() {
+	if t.lockFile != nil {
+		t.lockFile.Close()
+		t.lockFile = nil
+	}
+}
+
+//reopen the log file if it was renamed away, and take its size from disk
+func (t *EasyLog) _refreshFile(cfg fileConfig) {
+	if t.file == nil {
+		return
+	}
+
+	held, err := t.file.Stat()
+	if err != nil {
+		t._closeFile()
+		return
+	}
+
+	cur, err := os.Stat(filepath.Join(cfg.dir, cfg.name))
+	if err != nil || !os.SameFile(held, cur) {
+		t._closeFile()
+		return
+	}
+
+	t.fileSize = held.Size()
+}
@@ -0,0 +1,133 @@
+package easylog
+
+import "fmt"
+
+//Logger is a lightweight handle that logs through an EasyLog with a set of
+//fields attached to every entry. it is cheap to create and safe for
+//concurrent use.
+type Logger struct {
+	log    *EasyLog
+	fields Fields
+	//the key of a ForKey Logger, whose entries go to log's logger of key
+	key string
+}
+
+//return a Logger that attaches fields to every entry
+func (t *EasyLog) WithFields(fields Fields) *Logger {
+	return (&Logger{log: t}).WithFields(fields)
+}
+
+//return a new Logger with fields merged into the existing ones
+func (l *Logger) WithFields(fields Fields) *Logger {
+	merged := make(Fields, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return &Logger{log: l.log, fields: merged, key: l.key}
+}
+
+//the logger l's entries go to. a ForKey Logger looks its key up for every
+//entry, so the key counts as used and a logger closed by SetMaxOpenKeys is
+//opened again. pass k to _release once the entry is queued.
+func (l *Logger) _acquire() (log *EasyLog, k *keyLog) {
+	if l.key == "" {
+		return l.log, nil
+	}
+
+	k, err := l.log._acquireKey(l.key)
+	if err != nil {
+		if err != ErrClosed {
+			l.log._reportError(err)
+		}
+		return l.log, nil
+	}
+
+	return k.log, k
+}
+
+func (l *Logger) _release(k *keyLog) {
+	if k != nil {
+		l.log._releaseKey(k)
+	}
+}
+
+func (l *Logger) Print(args ...interface{}) {
+	log, k := l._acquire()
+	log._logs(InfoLevel, l.fields, fmt.Sprint(args...))
+	l._release(k)
+}
+
+func (l *Logger) Printf(format string, args ...interface{}) {
+	log, k := l._acquire()
+	log._logf(InfoLevel, l.fields, format, args...)
+	l._release(k)
+}
+
+func (l *Logger) Println(args ...interface{}) {
+	log, k := l._acquire()
+	log._logs(InfoLevel, l.fields, fmt.Sprintln(args...))
+	l._release(k)
+}
+
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	log, k := l._acquire()
+	log._logf(DebugLevel, l.fields, format, args...)
+	l._release(k)
+}
+
+func (l *Logger) Infof(format string, args ...interface{}) {
+	log, k := l._acquire()
+	log._logf(InfoLevel, l.fields, format, args...)
+	l._release(k)
+}
+
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	log, k := l._acquire()
+	log._logf(WarnLevel, l.fields, format, args...)
+	l._release(k)
+}
+
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	log, k := l._acquire()
+	log._logf(ErrorLevel, l.fields, format, args...)
+	l._release(k)
+}
+
+//return a child Logger bound to key/value pairs, e.g.
+//
+//  auth := log.With("module", "auth")
+//
+//the child shares the parent's file and goroutines. keys that are not
+//strings are formatted with fmt.Sprint, and a trailing key without a value
+//gets "(MISSING)".
+func (t *EasyLog) With(keyvals ...interface{}) *Logger {
+	return (&Logger{log: t}).With(keyvals...)
+}
+
+//return a child Logger with the key/value pairs added to the bound fields
+func (l *Logger) With(keyvals ...interface{}) *Logger {
+	fields := make(Fields, (len(keyvals)+1)/2)
+	_addKeyvals(fields, keyvals)
+
+	return l.WithFields(fields)
+}
+
+func _addKeyvals(fields Fields, keyvals []interface{}) {
+	for i := 0; i < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyvals[i])
+		}
+
+		var value interface{} = "(MISSING)"
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+
+		fields[key] = value
+	}
+}
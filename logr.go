@@ -0,0 +1,109 @@
+package easylog
+
+import (
+	"runtime"
+
+	"github.com/go-logr/logr"
+)
+
+//LogrSink adapts an EasyLog to github.com/go-logr/logr. V(0) entries are
+//logged at InfoLevel and higher verbosities at DebugLevel. names given to
+//WithName are joined with "/" into the "logger" field.
+type LogrSink struct {
+	log       *EasyLog
+	fields    Fields
+	name      string
+	callDepth int
+}
+
+var _ logr.CallDepthLogSink = (*LogrSink)(nil)
+
+//return a logr.LogSink that logs through t
+func (t *EasyLog) LogrSink() *LogrSink {
+	return &LogrSink{log: t}
+}
+
+//return a logr.Logger that logs through t
+func (t *EasyLog) Logr() logr.Logger {
+	return logr.New(t.LogrSink())
+}
+
+func (s *LogrSink) Init(info logr.RuntimeInfo) {
+	s.callDepth = info.CallDepth
+}
+
+func (s *LogrSink) Enabled(level int) bool {
+	return s.log._wanted(_fromLogrLevel(level), s.fields)
+}
+
+func (s *LogrSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s._log(_fromLogrLevel(level), msg, nil, keysAndValues)
+}
+
+func (s *LogrSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s._log(ErrorLevel, msg, err, keysAndValues)
+}
+
+func (s *LogrSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	c := *s
+	c.fields = _copyFields(s.fields)
+	_addKeyvals(c.fields, keysAndValues)
+
+	return &c
+}
+
+func (s *LogrSink) WithName(name string) logr.LogSink {
+	c := *s
+	if c.name != "" {
+		name = c.name + "/" + name
+	}
+	c.name = name
+
+	return &c
+}
+
+func (s *LogrSink) WithCallDepth(depth int) logr.LogSink {
+	c := *s
+	c.callDepth += depth
+
+	return &c
+}
+
+func (s *LogrSink) _log(level Level, msg string, err error, keysAndValues []interface{}) {
+	if !s.log._wanted(level, s.fields) {
+		return
+	}
+
+	fields := _copyFields(s.fields)
+	_addKeyvals(fields, keysAndValues)
+	if s.name != "" {
+		fields["logger"] = s.name
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+
+	//_log, Info or Error, then the logr.Logger method
+	if s.log.GetReportCaller() {
+		if pc, file, line, ok := runtime.Caller(2 + s.callDepth); ok {
+			frame := runtime.Frame{PC: pc, File: file, Line: line}
+			if fn := runtime.FuncForPC(pc); fn != nil {
+				frame.Function = fn.Name()
+			}
+			_addCaller(fields, frame)
+		}
+	}
+	if s.log._wantStack(level) {
+		fields["stack"] = _stacktrace(2+s.callDepth, s.log.GetStacktraceDepth())
+	}
+
+	s.log._logMsg(s.log._now(), level, msg, fields)
+}
+
+func _fromLogrLevel(level int) Level {
+	if level > 0 {
+		return DebugLevel
+	}
+
+	return InfoLevel
+}
@@ -0,0 +1,171 @@
+package easylog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+//a rotated file waiting for the compression worker, with the times of the
+//first and last writes to it
+type rotatedFile struct {
+	path        string
+	first, last time.Time
+}
+
+type manifest struct {
+	Files []manifestFile `json:"files"`
+}
+
+type manifestFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+//keep a manifest of the rotated files next to the log file, named after it
+//with a .manifest.json suffix, so archival and auditing jobs can check that
+//no file is missing or altered:
+//
+//  {"files": [{"name": "app.log.20240517120000.gz", "size": 1043, "sha256": "9f86d0...",
+//      "from": "2024-05-17T11:00:00.12Z", "to": "2024-05-17T11:59:59.87Z"}]}
+//
+//names are relative to the log directory. sizes and checksums are those of
+//the files on disk, after compression. from and to are the times of the
+//first and last writes to a file; a file continued after a restart starts at
+//the restart. the manifest is rewritten atomically after each rotation, and
+//files removed by retention or archiving are dropped from it.
+func (t *EasyLog) SetManifest(enable bool) error {
+	var v int32
+	if enable {
+		v = 1
+	}
+
+	atomic.StoreInt32(&t.manifest, v)
+
+	return nil
+}
+
+func (t *EasyLog) GetManifest() bool {
+	return atomic.LoadInt32(&t.manifest) == 1
+}
+
+func _manifestPath(cfg fileConfig) string {
+	return filepath.Join(cfg.root, cfg.name+".manifest.json")
+}
+
+//record the times of writes to the current file. only called from the
+//serve goroutine.
+func (t *EasyLog) _markWrite() {
+	if t.spanPath != t.filePath {
+		t.spanPath = t.filePath
+		t.fileFirst = time.Time{}
+	}
+
+	now := t._now()
+	if t.fileFirst.IsZero() {
+		t.fileFirst = now
+	}
+	t.fileLast = now
+}
+
+//add f to the manifest, if f != nil, and drop the files that are gone
+func (t *EasyLog) _updateManifest(f *rotatedFile) error {
+	if !t.GetManifest() {
+		return nil
+	}
+
+	t.manifestMu.Lock()
+	defer t.manifestMu.Unlock()
+
+	cfg := t._fileConfig()
+	path := _manifestPath(cfg)
+
+	var m manifest
+	if data, err := os.ReadFile(path); err == nil {
+		//a damaged manifest is rebuilt from the next rotation on
+		json.Unmarshal(data, &m)
+	}
+
+	changed := false
+	var prev manifestFile
+	kept := m.Files[:0]
+	for _, e := range m.Files {
+		if f != nil && e.Name == _rootRel(cfg.root, f.path) {
+			prev = e
+			changed = true
+			continue
+		}
+		if !_exists(filepath.Join(cfg.root, filepath.FromSlash(e.Name))) {
+			changed = true
+			continue
+		}
+		kept = append(kept, e)
+	}
+	m.Files = kept
+
+	if f != nil && _exists(f.path) {
+		e, err := _manifestEntry(cfg, f)
+		if err != nil {
+			return err
+		}
+		//files queued again after a restart have no times of their own
+		if e.From == "" {
+			e.From, e.To = prev.From, prev.To
+		}
+		m.Files = append(m.Files, e)
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(&m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, append(data, '\n'), cfg.fileMode); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := t.fs.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
+
+func _manifestEntry(cfg fileConfig, f *rotatedFile) (manifestFile, error) {
+	e := manifestFile{Name: _rootRel(cfg.root, f.path)}
+
+	r, err := os.Open(f.path)
+	if err != nil {
+		return e, err
+	}
+	defer r.Close()
+
+	h := sha256.New()
+	e.Size, err = io.Copy(h, r)
+	if err != nil {
+		return e, err
+	}
+	e.SHA256 = hex.EncodeToString(h.Sum(nil))
+
+	if !f.first.IsZero() {
+		e.From = f.first.Format(time.RFC3339Nano)
+		e.To = f.last.Format(time.RFC3339Nano)
+	}
+
+	return e, nil
+}
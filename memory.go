@@ -0,0 +1,116 @@
+package easylog
+
+import "sync/atomic"
+
+const defaultMaxBufferCap = 64 * 1024
+
+//BufferStats reports the memory held by queued log data
+type BufferStats struct {
+	//bytes accepted by Write but not yet written to disk
+	InFlight int64
+	//highest InFlight seen so far
+	PeakInFlight int64
+	//the limit set by SetMaxInFlight, 0 if unlimited
+	MaxInFlight int64
+	//buffers not returned to the pool because they exceeded SetMaxBufferCap
+	Discarded uint64
+	//buffers requested, and how many of them the pool had, so
+	//PoolHits/PoolGets is the hit rate. shards count in their logger's pool.
+	PoolGets uint64
+	PoolHits uint64
+	//capacity of the buffers returned to the pool and not taken out again.
+	//the garbage collector may free idle pooled buffers, they still count.
+	PoolRetained int64
+}
+
+//set the largest buffer capacity kept in the pool. buffers that grew beyond
+//it, e.g. by logging a huge payload once, are left to the garbage collector
+//instead of holding their memory in the pool.
+//if MaxBufferCap == 0, all buffers are pooled. the default is 64KB.
+func (t *EasyLog) SetMaxBufferCap(MaxBufferCap int64) error {
+	if MaxBufferCap < 0 {
+		MaxBufferCap = 0
+	}
+
+	atomic.StoreInt64(&t.maxBufferCap, MaxBufferCap)
+
+	return nil
+}
+
+//set a limit on bytes accepted by Write that are not yet on disk. when the
+//limit is reached, Write waits for a flush under the Block queue policy and
+//drops the entry under the drop policies. a single entry larger than the
+//limit is still accepted when nothing else is in flight.
+//if MaxInFlight == 0, memory is not limited.
+func (t *EasyLog) SetMaxInFlight(MaxInFlight int64) error {
+	if MaxInFlight < 0 {
+		MaxInFlight = 0
+	}
+
+	atomic.StoreInt64(&t.maxInFlight, MaxInFlight)
+
+	t.memMu.Lock()
+	t.memCond.Broadcast()
+	t.memMu.Unlock()
+
+	return nil
+}
+
+func (t *EasyLog) BufferStats() BufferStats {
+	t.memMu.Lock()
+	defer t.memMu.Unlock()
+
+	return BufferStats{
+		InFlight:     t.inFlight,
+		PeakInFlight: t.peakInFlight,
+		MaxInFlight:  atomic.LoadInt64(&t.maxInFlight),
+		Discarded:    atomic.LoadUint64(&t.discarded),
+		PoolGets:     atomic.LoadUint64(&t.pool.stats.gets),
+		PoolHits:     atomic.LoadUint64(&t.pool.stats.hits),
+		PoolRetained: atomic.LoadInt64(&t.pool.stats.retained),
+	}
+}
+
+//account for n more bytes in flight. if that exceeds the limit, wait for
+//room when wait is true, otherwise return false.
+func (t *EasyLog) _reserve(n int64, wait bool) bool {
+	t.memMu.Lock()
+	defer t.memMu.Unlock()
+
+	for {
+		max := atomic.LoadInt64(&t.maxInFlight)
+		if max <= 0 || t.inFlight == 0 || t.inFlight+n <= max {
+			break
+		}
+		if !wait {
+			return false
+		}
+		t.memCond.Wait()
+	}
+
+	t.inFlight += n
+	if t.inFlight > t.peakInFlight {
+		t.peakInFlight = t.inFlight
+	}
+
+	return true
+}
+
+//account for bytes the serve goroutine added to queued data, e.g. audit
+//headers or WriteSync entries. it never waits, the bytes are already in
+//memory.
+func (t *EasyLog) _grow(n int64) {
+	t.memMu.Lock()
+	t.inFlight += n
+	if t.inFlight > t.peakInFlight {
+		t.peakInFlight = t.inFlight
+	}
+	t.memMu.Unlock()
+}
+
+func (t *EasyLog) _release(n int64) {
+	t.memMu.Lock()
+	t.inFlight -= n
+	t.memCond.Broadcast()
+	t.memMu.Unlock()
+}
@@ -0,0 +1,72 @@
+package easylog
+
+import "fmt"
+
+//the field naming the component an entry comes from, e.g. set by
+//log.With("module", "db")
+const moduleKey = "module"
+
+//override the minimum level for entries whose "module" field is module, so
+//a noisy component can be turned up or down on its own. entries of other
+//modules and without the field use the level set by SetLevel.
+func (t *EasyLog) SetModuleLevel(module string, level Level) error {
+	if !level.valid() {
+		return fmt.Errorf("easylog: invalid level %d", int32(level))
+	}
+
+	t.moduleMu.Lock()
+	defer t.moduleMu.Unlock()
+
+	levels := t._moduleLevels()
+	updated := make(map[string]Level, len(levels)+1)
+	for k, v := range levels {
+		updated[k] = v
+	}
+	updated[module] = level
+	t.moduleLevels.Store(updated)
+
+	return nil
+}
+
+//remove the override of module, it uses the level set by SetLevel again
+func (t *EasyLog) ClearModuleLevel(module string) {
+	t.moduleMu.Lock()
+	defer t.moduleMu.Unlock()
+
+	levels := t._moduleLevels()
+	if _, ok := levels[module]; !ok {
+		return
+	}
+
+	updated := make(map[string]Level, len(levels))
+	for k, v := range levels {
+		if k != module {
+			updated[k] = v
+		}
+	}
+	t.moduleLevels.Store(updated)
+}
+
+//get the override of module. ok is false if it has none.
+func (t *EasyLog) GetModuleLevel(module string) (level Level, ok bool) {
+	level, ok = t._moduleLevels()[module]
+	return
+}
+
+func (t *EasyLog) _moduleLevels() map[string]Level {
+	levels, _ := t.moduleLevels.Load().(map[string]Level)
+	return levels
+}
+
+//minimum level of an entry with fields
+func (t *EasyLog) _levelFor(fields Fields) Level {
+	if levels := t._moduleLevels(); len(levels) > 0 {
+		if module, ok := fields[moduleKey].(string); ok {
+			if level, ok := levels[module]; ok {
+				return level
+			}
+		}
+	}
+
+	return t.GetLevel()
+}
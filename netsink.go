@@ -0,0 +1,192 @@
+package easylog
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+//NetworkSink forwards the log stream to a remote collector over TCP, UDP or
+//TLS. add it to a logger with AddOutput. lost connections are re-dialed with
+//exponential backoff, and while the collector is unreachable data goes to the
+//fallback writer, typically another EasyLog writing to a local file.
+type NetworkSink struct {
+	network string
+	addr    string
+
+	mu           sync.Mutex
+	tlsConfig    *tls.Config
+	fallback     io.Writer
+	dialTimeout  time.Duration
+	writeTimeout time.Duration
+	conn         net.Conn
+	backoff      time.Duration
+	nextDial     time.Time
+}
+
+const (
+	minNetBackoff = time.Millisecond * 500
+	maxNetBackoff = time.Minute
+)
+
+//create a sink for network "tcp", "udp" or "tls" and addr "host:port".
+//nothing is dialed until the first write.
+func NewNetworkSink(network, addr string) (*NetworkSink, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "tls":
+	default:
+		return nil, fmt.Errorf("easylog: unsupported network %q", network)
+	}
+
+	s := &NetworkSink{
+		network:      network,
+		addr:         addr,
+		dialTimeout:  time.Second * 3,
+		writeTimeout: time.Second * 3,
+	}
+
+	return s, nil
+}
+
+//set the TLS configuration used by the "tls" network
+func (s *NetworkSink) SetTLSConfig(cfg *tls.Config) {
+	s.mu.Lock()
+	s.tlsConfig = cfg
+	s.mu.Unlock()
+}
+
+//set the writer that receives data while the collector is unreachable
+func (s *NetworkSink) SetFallback(w io.Writer) {
+	s.mu.Lock()
+	s.fallback = w
+	s.mu.Unlock()
+}
+
+//set the dial and write timeouts. zero keeps the current value.
+func (s *NetworkSink) SetTimeouts(dial, write time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if dial > 0 {
+		s.dialTimeout = dial
+	}
+	if write > 0 {
+		s.writeTimeout = write
+	}
+}
+
+//send p to the collector, or to the fallback writer if that fails. an error
+//is returned when the connection fails, not for every batch diverted while
+//waiting to re-dial. without a fallback writer, every batch that isn't sent
+//returns an error, so a Spool around the sink retries it.
+func (s *NetworkSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		if time.Now().Before(s.nextDial) {
+			var cause error
+			if s.fallback == nil {
+				cause = errors.New("not connected, waiting to re-dial")
+			}
+			return s._writeFallback(p, cause)
+		}
+
+		if err := s._dial(); err != nil {
+			s._fail()
+			return s._writeFallback(p, err)
+		}
+	}
+
+	if err := s._send(p); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		s._fail()
+		return s._writeFallback(p, err)
+	}
+
+	s.backoff = 0
+
+	return len(p), nil
+}
+
+//close the connection to the collector
+func (s *NetworkSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+
+	return err
+}
+
+func (s *NetworkSink) _dial() error {
+	var err error
+
+	dialer := &net.Dialer{Timeout: s.dialTimeout}
+	if s.network == "tls" {
+		s.conn, err = tls.DialWithDialer(dialer, "tcp", s.addr, s.tlsConfig)
+	} else {
+		s.conn, err = dialer.Dial(s.network, s.addr)
+	}
+
+	return err
+}
+
+func (s *NetworkSink) _send(p []byte) error {
+	s.conn.SetWriteDeadline(time.Now().Add(s.writeTimeout))
+
+	if _, ok := s.conn.(*net.UDPConn); !ok {
+		_, err := s.conn.Write(p)
+		return err
+	}
+
+	//one datagram per line, so a batch never exceeds the datagram size
+	for len(p) > 0 {
+		line := p
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			line = p[:i+1]
+		}
+		p = p[len(line):]
+
+		if _, err := s.conn.Write(line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *NetworkSink) _fail() {
+	if s.backoff == 0 {
+		s.backoff = minNetBackoff
+	} else if s.backoff *= 2; s.backoff > maxNetBackoff {
+		s.backoff = maxNetBackoff
+	}
+
+	s.nextDial = time.Now().Add(s.backoff)
+}
+
+func (s *NetworkSink) _writeFallback(p []byte, cause error) (int, error) {
+	if s.fallback != nil {
+		if _, err := s.fallback.Write(p); err != nil && cause == nil {
+			cause = err
+		}
+	}
+
+	if cause != nil {
+		return 0, fmt.Errorf("easylog: send to %s %s: %w", s.network, s.addr, cause)
+	}
+
+	return len(p), nil
+}
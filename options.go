@@ -0,0 +1,219 @@
+package easylog
+
+import (
+	"os"
+	"time"
+)
+
+//Options holds the settings applied when a logger is constructed.
+//zero values select the defaults. every setting can still be changed at
+//runtime through the matching Set method.
+type Options struct {
+	//directory and name of the active log file. default "" and "log.txt"
+	Dir      string
+	FileName string
+	//see SetFallbackDir, SetDailyDirs and SetLazyCreate
+	FallbackDir string
+	DailyDirs   bool
+	LazyCreate  bool
+	//permissions of created directories and files. default 0755 and 0644
+	DirMode  os.FileMode
+	FileMode os.FileMode
+
+	//rotation and retention, see SetMaxFileSize, SetMaxFileCount,
+	//SetMaxFileAge, SetMaxTotalSize, SetRotateInterval, SetRotateNamePattern,
+	//SetCompressRotated, SetCompressionCodec and SetManifest
+	MaxFileSize       int64
+	MaxFileCount      int64
+	MaxFileAge        time.Duration
+	MaxTotalSize      int64
+	RotateInterval    time.Duration
+	RotateNamePattern string
+	CompressRotated   bool
+	CompressionCodec  CompressionCodec
+	Manifest          bool
+
+	//capacity of the write queue, default 1024
+	BufferLen int
+	//interval of periodic flushes, default 1 second
+	FlushFreq time.Duration
+	//see SetMaxBufferCap and SetBufferPooling
+	MaxBufferCap int64
+	NoBufferPool bool
+	//see SetMaxEntrySize, SetBatchSize, SetMaxBatchDelay and SetFlushEvery
+	MaxEntrySize  int64
+	BatchSize     int64
+	MaxBatchDelay time.Duration
+	FlushEvery    int
+
+	Level       Level
+	Format      Format
+	QueuePolicy QueuePolicy
+	SyncPolicy  SyncPolicy
+
+	//see SetWriteTimeout
+	WriteTimeout time.Duration
+
+	//see SetTimeFormat, SetPrefix, SetCSVColumns, SetEscapeMode and
+	//SetOrderedTimestamps
+	TimeFormat        string
+	Prefix            string
+	CSVColumns        []string
+	EscapeMode        EscapeMode
+	OrderedTimestamps bool
+
+	//see SetGlobalFields and SetHostFields
+	GlobalFields map[string]string
+	HostFields   bool
+
+	//see SetJSONShadow and SetRecordSeparator
+	JSONShadow      string
+	RecordSeparator string
+
+	//see SetDedupWindow
+	DedupWindow time.Duration
+
+	//see SetRecentSize
+	RecentSize int
+
+	//see SetFileHeader and SetFileFooter
+	FileHeader func() []byte
+	FileFooter func() []byte
+
+	//see SetEncoder
+	Encoder Encoder
+	//see SetShards
+	Shards   int
+	ShardKey string
+	//see SetEncoding
+	Encoding Transcoder
+
+	//run background work on a shared Scheduler, see NewScheduler
+	Scheduler *Scheduler
+}
+
+//the environment variable overriding Options.Level, e.g. EASYLOG_LEVEL=warn
+const LevelEnv = "EASYLOG_LEVEL"
+
+//create a logger from opts. it returns an error if a setting is invalid or
+//the log directory cannot be created.
+func NewLogWithOptions(opts Options) (*EasyLog, error) {
+	return _newLogWithClock(opts, realClock{})
+}
+
+//like NewLogWithOptions, with the time of c. a Scheduler brings its own clock.
+func _newLogWithClock(opts Options, c clock) (*EasyLog, error) {
+	if opts.BufferLen <= 0 {
+		opts.BufferLen = 1024
+	}
+
+	if opts.FlushFreq == 0 {
+		opts.FlushFreq = time.Second
+	} else if opts.FlushFreq < time.Millisecond*10 {
+		opts.FlushFreq = time.Millisecond * 10
+	}
+
+	ins := _newLog(opts.BufferLen, opts.FlushFreq)
+	ins.clock = c
+	if opts.Scheduler != nil {
+		ins.sched = opts.Scheduler
+		ins.clock = schedClock{opts.Scheduler}
+	}
+	ins._start()
+
+	if err := ins._apply(opts); err != nil {
+		ins.Close()
+		return nil, err
+	}
+
+	//deployments can change verbosity without code changes. invalid
+	//values are ignored.
+	if v := os.Getenv(LevelEnv); v != "" {
+		if level, err := ParseLevel(v); err == nil {
+			ins.SetLevel(level)
+		}
+	}
+
+	return ins, nil
+}
+
+func (t *EasyLog) _apply(opts Options) error {
+	t.SetPermissions(opts.DirMode, opts.FileMode)
+	t.SetLazyCreate(opts.LazyCreate)
+
+	_, name := t.GetDir()
+	if opts.FileName != "" {
+		name = opts.FileName
+	}
+
+	if opts.Dir != "" {
+		if err := t.SetDir(opts.Dir, name); err != nil {
+			return err
+		}
+	} else {
+		t.mu.Lock()
+		t.fileName = name
+		t.mu.Unlock()
+	}
+
+	if opts.FallbackDir != "" {
+		if err := t.SetFallbackDir(opts.FallbackDir); err != nil {
+			return err
+		}
+	}
+
+	if opts.MaxFileSize > 0 {
+		t.SetMaxFileSize(opts.MaxFileSize)
+	}
+
+	if opts.MaxBufferCap > 0 {
+		t.SetMaxBufferCap(opts.MaxBufferCap)
+	}
+
+	set := []func() error{
+		func() error { return t.SetDailyDirs(opts.DailyDirs) },
+		func() error { return t.SetMaxFileCount(opts.MaxFileCount) },
+		func() error { return t.SetMaxFileAge(opts.MaxFileAge) },
+		func() error { return t.SetMaxTotalSize(opts.MaxTotalSize) },
+		func() error { return t.SetRotateInterval(opts.RotateInterval) },
+		func() error { return t.SetRotateNamePattern(opts.RotateNamePattern) },
+		func() error { return t.SetCompressRotated(opts.CompressRotated) },
+		func() error { return t.SetCompressionCodec(opts.CompressionCodec) },
+		func() error { return t.SetManifest(opts.Manifest) },
+		func() error { return t.SetMaxEntrySize(opts.MaxEntrySize) },
+		func() error { return t.SetBatchSize(opts.BatchSize) },
+		func() error { return t.SetMaxBatchDelay(opts.MaxBatchDelay) },
+		func() error { return t.SetFlushEvery(opts.FlushEvery) },
+		func() error { return t.SetBufferPooling(!opts.NoBufferPool) },
+		func() error { return t.SetLevel(opts.Level) },
+		func() error { return t.SetFormat(opts.Format) },
+		func() error { return t.SetQueuePolicy(opts.QueuePolicy) },
+		func() error { return t.SetWriteTimeout(opts.WriteTimeout) },
+		func() error { return t.SetSyncPolicy(opts.SyncPolicy) },
+		func() error { return t.SetTimeFormat(opts.TimeFormat) },
+		func() error { return t.SetPrefix(opts.Prefix) },
+		func() error { return t.SetCSVColumns(opts.CSVColumns...) },
+		func() error { return t.SetEscapeMode(opts.EscapeMode) },
+		func() error { return t.SetOrderedTimestamps(opts.OrderedTimestamps) },
+		func() error { return t.SetEncoder(opts.Encoder) },
+		func() error { return t.SetEncoding(opts.Encoding) },
+		func() error { return t.SetGlobalFields(opts.GlobalFields) },
+		func() error { return t.SetHostFields(opts.HostFields) },
+		func() error { return t.SetJSONShadow(opts.JSONShadow) },
+		func() error { return t.SetRecordSeparator(opts.RecordSeparator) },
+		func() error { return t.SetDedupWindow(opts.DedupWindow) },
+		func() error { return t.SetRecentSize(opts.RecentSize) },
+		func() error { return t.SetFileHeader(opts.FileHeader) },
+		func() error { return t.SetFileFooter(opts.FileFooter) },
+		//the shards take the settings above
+		func() error { return t.SetShards(opts.Shards, opts.ShardKey) },
+	}
+
+	for _, fn := range set {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
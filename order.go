@@ -0,0 +1,45 @@
+package easylog
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+//stamp leveled entries when they are queued instead of when they are
+//logged, under one lock, so their order in the file is the order of their
+//timestamps even with many goroutines logging at once, and every timestamp
+//is later than the one before, by at least a nanosecond, even if the clock
+//steps back. use a time format showing nanoseconds, e.g. time.RFC3339Nano,
+//to reconstruct timelines from it. loggers then encode one entry at a time,
+//so encoders and redactors must not log to the same logger.
+func (t *EasyLog) SetOrderedTimestamps(enable bool) error {
+	var v int32
+	if enable {
+		v = 1
+	}
+
+	atomic.StoreInt32(&t.ordered, v)
+
+	return nil
+}
+
+func (t *EasyLog) GetOrderedTimestamps() bool {
+	return atomic.LoadInt32(&t.ordered) == 1
+}
+
+//take the enqueue timestamp and the lock held until the entry is queued.
+//without ordered timestamps, now is kept and unlock is nil.
+func (t *EasyLog) _stamp(now time.Time) (stamped time.Time, unlock func()) {
+	if !t.GetOrderedTimestamps() {
+		return now, nil
+	}
+
+	t.stampMu.Lock()
+	now = t._now()
+	if wall := now.UnixNano(); wall <= t.lastStamp {
+		now = now.Add(time.Duration(t.lastStamp + 1 - wall))
+	}
+	t.lastStamp = now.UnixNano()
+
+	return now, t.stampMu.Unlock
+}
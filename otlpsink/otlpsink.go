@@ -0,0 +1,403 @@
+//Package otlpsink exports easylog entries as OpenTelemetry log records.
+//
+//records are sent with the OTLP/HTTP protocol in its JSON encoding, which
+//every OpenTelemetry collector accepts on port 4318, so the package needs no
+//OpenTelemetry or gRPC dependency. the exporter is added to a logger as a
+//hook:
+//
+//  exp := otlpsink.New("http://collector:4318/v1/logs")
+//  exp.SetResource(map[string]string{"service.name": "checkout"})
+//  log.AddHook(exp.Hook)
+//  defer exp.Close()
+//
+//entries are batched and sent from a background goroutine, so logging never
+//waits for the collector. when the exporter falls behind, new entries are
+//dropped and counted.
+package otlpsink
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/carr123/easylog"
+)
+
+var ErrClosed = errors.New("otlpsink: exporter is closed")
+
+//Exporter batches entries and posts them to an OTLP/HTTP logs endpoint
+type Exporter struct {
+	//64-bit atomics first, to keep them aligned on 32-bit platforms
+	dropped uint64
+
+	endpoint string
+
+	mu        sync.Mutex
+	client    *http.Client
+	headers   map[string]string
+	resource  map[string]string
+	scope     string
+	batchSize int
+	interval  time.Duration
+
+	entries chan easylog.Entry
+	flushCh chan chan error
+	batchCh chan struct{}
+	quit    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	err     error
+}
+
+//create an exporter posting to endpoint, the full URL of the logs path,
+//e.g. "http://localhost:4318/v1/logs"
+func New(endpoint string) *Exporter {
+	e := &Exporter{
+		endpoint:  endpoint,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		scope:     "github.com/carr123/easylog",
+		batchSize: 512,
+		interval:  5 * time.Second,
+		entries:   make(chan easylog.Entry, 4096),
+		flushCh:   make(chan chan error),
+		batchCh:   make(chan struct{}, 1),
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	go e._serve()
+
+	return e
+}
+
+//set the client used to post batches. the default has a 10 second timeout.
+func (e *Exporter) SetHTTPClient(client *http.Client) {
+	e.mu.Lock()
+	e.client = client
+	e.mu.Unlock()
+}
+
+//set headers sent with every request, e.g. for authentication
+func (e *Exporter) SetHeaders(headers map[string]string) {
+	e.mu.Lock()
+	e.headers = headers
+	e.mu.Unlock()
+}
+
+//set the resource attributes, such as service.name, of all records
+func (e *Exporter) SetResource(attrs map[string]string) {
+	e.mu.Lock()
+	e.resource = attrs
+	e.mu.Unlock()
+}
+
+//set the largest batch and the longest time an entry waits before it is
+//sent. the defaults are 512 records and 5 seconds.
+func (e *Exporter) SetBatch(size int, interval time.Duration) {
+	e.mu.Lock()
+	if size > 0 {
+		e.batchSize = size
+	}
+	if interval > 0 {
+		e.interval = interval
+	}
+	e.mu.Unlock()
+
+	//the background goroutine is already running with the old interval
+	select {
+	case e.batchCh <- struct{}{}:
+	default:
+	}
+}
+
+//number of entries dropped because the exporter fell behind or a batch
+//could not be sent
+func (e *Exporter) Dropped() uint64 {
+	return atomic.LoadUint64(&e.dropped)
+}
+
+//queue entry for export. it has the signature of easylog.Hook.
+func (e *Exporter) Hook(entry *easylog.Entry) error {
+	c := *entry
+	if entry.Fields != nil {
+		c.Fields = make(easylog.Fields, len(entry.Fields))
+		for k, v := range entry.Fields {
+			c.Fields[k] = v
+		}
+	}
+
+	select {
+	case <-e.quit:
+	case e.entries <- c:
+	default:
+		atomic.AddUint64(&e.dropped, 1)
+	}
+
+	return nil
+}
+
+//send all queued entries and return the error of the request, if any
+func (e *Exporter) Flush() error {
+	ch := make(chan error, 1)
+
+	select {
+	case e.flushCh <- ch:
+		return <-ch
+	case <-e.done:
+		return ErrClosed
+	}
+}
+
+//send queued entries and stop the background goroutine
+func (e *Exporter) Close() error {
+	e.once.Do(func() {
+		close(e.quit)
+		<-e.done
+	})
+
+	return e.err
+}
+
+func (e *Exporter) _serve() {
+	defer close(e.done)
+
+	e.mu.Lock()
+	interval := e.interval
+	e.mu.Unlock()
+
+	tm := time.NewTicker(interval)
+	defer tm.Stop()
+
+	var batch []easylog.Entry
+	send := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := e._post(batch)
+		if err != nil {
+			atomic.AddUint64(&e.dropped, uint64(len(batch)))
+		}
+		batch = batch[:0]
+		return err
+	}
+
+	drain := func() {
+		for {
+			select {
+			case entry := <-e.entries:
+				batch = append(batch, entry)
+			default:
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case entry := <-e.entries:
+			batch = append(batch, entry)
+			e.mu.Lock()
+			full := len(batch) >= e.batchSize
+			e.mu.Unlock()
+			if full {
+				send()
+			}
+		case <-tm.C:
+			send()
+		case <-e.batchCh:
+			e.mu.Lock()
+			interval = e.interval
+			e.mu.Unlock()
+			tm.Reset(interval)
+		case ch := <-e.flushCh:
+			drain()
+			ch <- send()
+		case <-e.quit:
+			drain()
+			e.err = send()
+			return
+		}
+	}
+}
+
+func (e *Exporter) _post(batch []easylog.Entry) error {
+	e.mu.Lock()
+	client, headers, resource, scope := e.client, e.headers, e.resource, e.scope
+	e.mu.Unlock()
+
+	body, err := json.Marshal(_request(batch, resource, scope))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("otlpsink: %s: %s", e.endpoint, resp.Status)
+	}
+
+	return nil
+}
+
+//the JSON encoding of an ExportLogsServiceRequest
+type exportRequest struct {
+	ResourceLogs []resourceLogs `json:"resourceLogs"`
+}
+
+type resourceLogs struct {
+	Resource  resource    `json:"resource"`
+	ScopeLogs []scopeLogs `json:"scopeLogs"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes,omitempty"`
+}
+
+type scopeLogs struct {
+	Scope      scope       `json:"scope"`
+	LogRecords []logRecord `json:"logRecords"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type logRecord struct {
+	TimeUnixNano   string     `json:"timeUnixNano"`
+	SeverityNumber int        `json:"severityNumber"`
+	SeverityText   string     `json:"severityText"`
+	Body           anyValue   `json:"body"`
+	Attributes     []keyValue `json:"attributes,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func _request(batch []easylog.Entry, attrs map[string]string, scopeName string) exportRequest {
+	var res resource
+	for _, k := range _sortedKeys(attrs) {
+		res.Attributes = append(res.Attributes, keyValue{k, _value(attrs[k])})
+	}
+
+	records := make([]logRecord, 0, len(batch))
+	for _, entry := range batch {
+		rec := logRecord{
+			TimeUnixNano:   strconv.FormatInt(entry.Time.UnixNano(), 10),
+			SeverityNumber: _severity(entry.Level),
+			SeverityText:   entry.Level.String(),
+			Body:           _value(entry.Message),
+		}
+		keys := make([]string, 0, len(entry.Fields))
+		for k := range entry.Fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			rec.Attributes = append(rec.Attributes, keyValue{k, _value(entry.Fields[k])})
+		}
+		records = append(records, rec)
+	}
+
+	return exportRequest{ResourceLogs: []resourceLogs{{
+		Resource:  res,
+		ScopeLogs: []scopeLogs{{Scope: scope{Name: scopeName}, LogRecords: records}},
+	}}}
+}
+
+//severity numbers of the OpenTelemetry log data model
+func _severity(level easylog.Level) int {
+	switch level {
+	case easylog.DebugLevel:
+		return 5
+	case easylog.InfoLevel:
+		return 9
+	case easylog.WarnLevel:
+		return 13
+	case easylog.ErrorLevel:
+		return 17
+	case easylog.PanicLevel:
+		return 22
+	case easylog.FatalLevel:
+		return 23
+	}
+
+	return 0
+}
+
+func _value(v interface{}) anyValue {
+	switch x := v.(type) {
+	case string:
+		return anyValue{StringValue: &x}
+	case bool:
+		return anyValue{BoolValue: &x}
+	case int:
+		s := strconv.FormatInt(int64(x), 10)
+		return anyValue{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(x, 10)
+		return anyValue{IntValue: &s}
+	case int32:
+		s := strconv.FormatInt(int64(x), 10)
+		return anyValue{IntValue: &s}
+	case uint:
+		s := strconv.FormatUint(uint64(x), 10)
+		return anyValue{IntValue: &s}
+	case uint32:
+		s := strconv.FormatUint(uint64(x), 10)
+		return anyValue{IntValue: &s}
+	case float64:
+		return anyValue{DoubleValue: &x}
+	case float32:
+		f := float64(x)
+		return anyValue{DoubleValue: &f}
+	case error:
+		s := x.Error()
+		return anyValue{StringValue: &s}
+	case fmt.Stringer:
+		s := x.String()
+		return anyValue{StringValue: &s}
+	}
+
+	s := fmt.Sprint(v)
+	return anyValue{StringValue: &s}
+}
+
+func _sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
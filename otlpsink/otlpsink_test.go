@@ -0,0 +1,164 @@
+package otlpsink
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/carr123/easylog"
+)
+
+//collector records the bodies posted to it and answers with status
+type collector struct {
+	mu     sync.Mutex
+	posts  []map[string]interface{}
+	status int
+	got    chan struct{}
+}
+
+func newCollector(tb testing.TB, status int) (*collector, *httptest.Server) {
+	c := &collector{status: status, got: make(chan struct{}, 100)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			tb.Errorf("posted body: %v", err)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			tb.Errorf("Content-Type is %q", ct)
+		}
+
+		c.mu.Lock()
+		c.posts = append(c.posts, body)
+		c.mu.Unlock()
+
+		w.WriteHeader(c.status)
+		c.got <- struct{}{}
+	}))
+	tb.Cleanup(srv.Close)
+
+	return c, srv
+}
+
+//wait for the next post
+func (c *collector) wait(tb testing.TB, timeout time.Duration) {
+	tb.Helper()
+
+	select {
+	case <-c.got:
+	case <-time.After(timeout):
+		tb.Fatal("nothing was posted")
+	}
+}
+
+//the log records of the i-th post
+func (c *collector) records(tb testing.TB, i int) []interface{} {
+	tb.Helper()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rl := c.posts[i]["resourceLogs"].([]interface{})[0].(map[string]interface{})
+	sl := rl["scopeLogs"].([]interface{})[0].(map[string]interface{})
+
+	return sl["logRecords"].([]interface{})
+}
+
+func entry(msg string) *easylog.Entry {
+	return &easylog.Entry{Time: time.Unix(1, 5), Level: easylog.WarnLevel, Message: msg}
+}
+
+func TestBatchSize(t *testing.T) {
+	c, srv := newCollector(t, http.StatusOK)
+	exp := New(srv.URL)
+	defer exp.Close()
+	exp.SetBatch(3, time.Hour)
+
+	for i := 0; i < 7; i++ {
+		exp.Hook(entry("m"))
+	}
+	c.wait(t, 5*time.Second)
+	c.wait(t, 5*time.Second)
+	if err := exp.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	c.wait(t, time.Second)
+
+	for i, want := range []int{3, 3, 1} {
+		if got := len(c.records(t, i)); got != want {
+			t.Errorf("post %d has %d records, want %d", i, got, want)
+		}
+	}
+}
+
+func TestBatchInterval(t *testing.T) {
+	c, srv := newCollector(t, http.StatusOK)
+	exp := New(srv.URL)
+	defer exp.Close()
+	//once a flush is answered, the background goroutine runs with the
+	//default interval of 5 seconds
+	if err := exp.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	exp.SetBatch(100, 20*time.Millisecond)
+
+	exp.Hook(entry("m"))
+	c.wait(t, 2*time.Second)
+	if got := len(c.records(t, 0)); got != 1 {
+		t.Errorf("post has %d records, want 1", got)
+	}
+}
+
+func TestPayload(t *testing.T) {
+	c, srv := newCollector(t, http.StatusOK)
+	exp := New(srv.URL)
+	defer exp.Close()
+	exp.SetResource(map[string]string{"service.name": "checkout"})
+
+	e := entry("paid")
+	e.Fields = easylog.Fields{"order": 42, "ok": true, "user": "ann"}
+	exp.Hook(e)
+	if err := exp.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	c.wait(t, time.Second)
+
+	want := `{"resourceLogs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"checkout"}}]},` +
+		`"scopeLogs":[{"scope":{"name":"github.com/carr123/easylog"},"logRecords":[{"timeUnixNano":"1000000005",` +
+		`"severityNumber":13,"severityText":"WARN","body":{"stringValue":"paid"},"attributes":[` +
+		`{"key":"ok","value":{"boolValue":true}},{"key":"order","value":{"intValue":"42"}},` +
+		`{"key":"user","value":{"stringValue":"ann"}}]}]}]}]}`
+
+	//marshaled maps have sorted keys, compare in that form
+	var norm interface{}
+	if err := json.Unmarshal([]byte(want), &norm); err != nil {
+		t.Fatal(err)
+	}
+	wantNorm, _ := json.Marshal(norm)
+
+	c.mu.Lock()
+	got, _ := json.Marshal(c.posts[0])
+	c.mu.Unlock()
+	if string(got) != string(wantNorm) {
+		t.Errorf("posted\n%s\nwant\n%s", got, wantNorm)
+	}
+}
+
+func TestDroppedAfterFailedPost(t *testing.T) {
+	c, srv := newCollector(t, http.StatusInternalServerError)
+	exp := New(srv.URL)
+	defer exp.Close()
+
+	exp.Hook(entry("a"))
+	exp.Hook(entry("b"))
+	if err := exp.Flush(); err == nil {
+		t.Fatal("a failed post returned no error")
+	}
+	c.wait(t, time.Second)
+
+	if got := exp.Dropped(); got != 2 {
+		t.Errorf("Dropped is %d, want 2", got)
+	}
+}
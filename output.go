@@ -0,0 +1,64 @@
+package easylog
+
+import (
+	"bytes"
+	"io"
+)
+
+//add a writer that receives the same log stream as the rotating file.
+//data is written in the same batches as the file, one batch at a time, from
+//the serve goroutine or the goroutines of the shards, see SetShards. a Spool
+//takes the logger's record separator.
+func (t *EasyLog) AddOutput(w io.Writer) error {
+	if w == nil {
+		return nil
+	}
+	if s, ok := w.(*Spool); ok {
+		s.SetRecordSeparator(t.GetRecordSeparator())
+	}
+
+	t.outMu.Lock()
+	defer t.outMu.Unlock()
+
+	outputs := make([]io.Writer, 0, len(t.outputs)+1)
+	outputs = append(outputs, t.outputs...)
+	t.outputs = append(outputs, w)
+
+	return nil
+}
+
+//remove a writer previously added by AddOutput
+func (t *EasyLog) RemoveOutput(w io.Writer) error {
+	t.outMu.Lock()
+	defer t.outMu.Unlock()
+
+	outputs := make([]io.Writer, 0, len(t.outputs))
+	for _, v := range t.outputs {
+		if v != w {
+			outputs = append(outputs, v)
+		}
+	}
+	t.outputs = outputs
+
+	return nil
+}
+
+func (t *EasyLog) _writeOutputs(data *bytes.Buffer) {
+	o := t._owner()
+	o.outMu.Lock()
+	outputs := o.outputs
+	o.outMu.Unlock()
+
+	if len(outputs) == 0 {
+		return
+	}
+	//the shards of a logger flush concurrently
+	o.teeMu.Lock()
+	defer o.teeMu.Unlock()
+
+	for _, w := range outputs {
+		if _, err := w.Write(data.Bytes()); err != nil {
+			t._reportError(err)
+		}
+	}
+}
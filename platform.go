@@ -0,0 +1,18 @@
+package easylog
+
+import "os"
+
+//platform holds the file operations whose behavior differs between operating
+//systems. rotation and multi-process mode only go through it, so a test can
+//give a logger a fake one, e.g. to fail renames the way windows does.
+type platform interface {
+	//rename the active log file. other processes may still hold it open.
+	Rename(oldpath, newpath string) error
+	//whether Lock is supported
+	CanLock() bool
+	//take an exclusive advisory lock on f, waiting for other processes
+	Lock(f *os.File) error
+	Unlock(f *os.File) error
+	//flush f's data to stable storage
+	Sync(f *os.File) error
+}
@@ -0,0 +1,23 @@
+package easylog
+
+import (
+	"os"
+	"syscall"
+)
+
+var osPlatform platform = linuxPlatform{}
+
+type linuxPlatform struct {
+	flockPlatform
+}
+
+//the log is only appended to, fdatasync skips the inode times but still
+//syncs the file size
+func (linuxPlatform) Sync(f *os.File) error {
+	for {
+		err := syscall.Fdatasync(int(f.Fd()))
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
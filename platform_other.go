@@ -0,0 +1,34 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package easylog
+
+import "os"
+
+var osPlatform platform = plainPlatform{}
+
+//no SIGHUP to handle by default, e.g. on js
+var reopenSignals []os.Signal
+
+//no file locking, e.g. on plan9 and js
+type plainPlatform struct{}
+
+func (plainPlatform) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (plainPlatform) CanLock() bool {
+	return false
+}
+
+func (plainPlatform) Lock(f *os.File) error {
+	return errLockUnsupported
+}
+
+func (plainPlatform) Unlock(f *os.File) error {
+	return nil
+}
+
+func (plainPlatform) Sync(f *os.File) error {
+	return f.Sync()
+}
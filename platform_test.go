@@ -0,0 +1,205 @@
+package easylog
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+//fakePlatform fails renames and locks on demand, the way another process
+//holding the log file open or locked makes them fail
+type fakePlatform struct {
+	mu        sync.Mutex
+	renameErr error
+	renames   int
+	noLock    bool
+	lockErr   error
+	locks     int
+}
+
+func (p *fakePlatform) Rename(oldpath, newpath string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.renames++
+	if p.renameErr != nil {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: p.renameErr}
+	}
+
+	return os.Rename(oldpath, newpath)
+}
+
+func (p *fakePlatform) CanLock() bool {
+	return !p.noLock
+}
+
+func (p *fakePlatform) Lock(f *os.File) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.locks++
+	return p.lockErr
+}
+
+func (p *fakePlatform) Unlock(f *os.File) error {
+	return nil
+}
+
+func (p *fakePlatform) Sync(f *os.File) error {
+	return f.Sync()
+}
+
+func (p *fakePlatform) set(fn func(p *fakePlatform)) {
+	p.mu.Lock()
+	fn(p)
+	p.mu.Unlock()
+}
+
+//give l a fake platform on its serve goroutine, which owns l.fs
+func useFakePlatform(tb testing.TB, l *EasyLog) *fakePlatform {
+	tb.Helper()
+
+	p := &fakePlatform{}
+	if err := l._do(func(data *bytes.Buffer) error {
+		l.fs = p
+		return nil
+	}); err != nil {
+		tb.Fatal(err)
+	}
+
+	return p
+}
+
+//errors reported through OnError
+type errorLog struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (e *errorLog) add(err error) {
+	e.mu.Lock()
+	e.errs = append(e.errs, err)
+	e.mu.Unlock()
+}
+
+func (e *errorLog) contains(s string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, err := range e.errs {
+		if strings.Contains(err.Error(), s) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRenameWhileOpen(t *testing.T) {
+	c := newFakeClock(time.Now())
+	l := newTestLog(t, Options{MaxFileSize: 1 << 20}, c)
+	p := useFakePlatform(t, l)
+	p.set(func(p *fakePlatform) { p.renameErr = errors.New("file is held open by another process") })
+	var errs errorLog
+	l.OnError(errs.add)
+	dir, name := l.GetDir()
+
+	entry := func(c byte) []byte {
+		return bytes.Repeat([]byte{c}, 600*1024)
+	}
+
+	//the second entry doesn't fit, but the rename fails: the file grows
+	//and the rename is retried on later flushes
+	for i, ch := range []byte("abc") {
+		l.Write(entry(ch))
+		if err := l.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		c.Advance(time.Second)
+
+		if renames := i; p.renames != renames {
+			t.Fatalf("after entry %d: %d renames, want %d", i+1, p.renames, renames)
+		}
+	}
+	if !errs.contains("retrying") {
+		t.Fatalf("failed renames were not reported: %v", errs.errs)
+	}
+
+	//the third failure rotates by copy and truncate instead
+	l.Write(entry('d'))
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if p.renames != 3 {
+		t.Fatalf("%d renames, want 3", p.renames)
+	}
+	if !errs.contains("copy-truncate") {
+		t.Fatalf("copy-truncate was not reported: %v", errs.errs)
+	}
+
+	rotated, _ := filepath.Glob(filepath.Join(dir, name+".*"))
+	if len(rotated) != 1 {
+		t.Fatalf("rotated files %v, want one", rotated)
+	}
+	b, err := os.ReadFile(rotated[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 3 * (600*1024 + 1); len(b) != want || b[0] != 'a' || b[len(b)-2] != 'c' {
+		t.Fatalf("rotated file holds %d bytes, want %d of a, b and c", len(b), want)
+	}
+	if got := readLog(t, l); len(got) != 600*1024+1 || got[0] != 'd' {
+		t.Fatalf("active file holds %d bytes, want the d entry", len(got))
+	}
+}
+
+func TestLockFailure(t *testing.T) {
+	l := newTestLog(t, Options{}, realClock{})
+	p := useFakePlatform(t, l)
+	if err := l.SetMultiProcess(true); err != nil {
+		t.Fatal(err)
+	}
+
+	lockErr := errors.New("lock is held")
+	p.set(func(p *fakePlatform) { p.lockErr = lockErr })
+
+	l.Write([]byte("kept"))
+	if err := l.Flush(); !errors.Is(err, lockErr) {
+		t.Fatalf("Flush returned %v, want the lock error", err)
+	}
+	if err := l.LastError(); !errors.Is(err, lockErr) {
+		t.Fatalf("LastError returned %v, want the lock error", err)
+	}
+	if got := readLog(t, l); got != "" {
+		t.Fatalf("written without the lock: %q", got)
+	}
+
+	//the entry was kept for a retry
+	p.set(func(p *fakePlatform) { p.lockErr = nil })
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readLog(t, l); got != "kept\n" {
+		t.Fatalf("the file holds %q, want %q", got, "kept\n")
+	}
+	if l.LastError() != nil {
+		t.Fatalf("LastError still returns %v", l.LastError())
+	}
+}
+
+func TestLockUnsupported(t *testing.T) {
+	l := newTestLog(t, Options{}, realClock{})
+	p := useFakePlatform(t, l)
+	p.set(func(p *fakePlatform) { p.noLock = true })
+
+	if err := l.SetMultiProcess(true); err != errLockUnsupported {
+		t.Fatalf("SetMultiProcess returned %v, want %v", err, errLockUnsupported)
+	}
+	if l.GetMultiProcess() {
+		t.Fatal("multi-process mode is enabled")
+	}
+}
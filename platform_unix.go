@@ -0,0 +1,41 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package easylog
+
+import (
+	"os"
+	"syscall"
+)
+
+//the signal ReopenOnSignal handles by default
+var reopenSignals = []os.Signal{syscall.SIGHUP}
+
+//unix renames open files fine, the writers keep their handles
+type flockPlatform struct{}
+
+func (flockPlatform) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (flockPlatform) CanLock() bool {
+	return true
+}
+
+func (flockPlatform) Lock(f *os.File) error {
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+func (flockPlatform) Unlock(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
+
+//on darwin this is F_FULLFSYNC, a plain fsync leaves data in the drive cache
+func (flockPlatform) Sync(f *os.File) error {
+	return f.Sync()
+}
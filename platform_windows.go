@@ -0,0 +1,77 @@
+package easylog
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var osPlatform platform = windowsPlatform{}
+
+//the signal ReopenOnSignal handles by default
+var reopenSignals = []os.Signal{syscall.SIGHUP}
+
+var (
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+const (
+	lockfileExclusiveLock = 0x2
+	lockAll               = 0xFFFFFFFF
+	errSharingViolation   = syscall.Errno(32)
+)
+
+//windows refuses to rename a file that is open without FILE_SHARE_DELETE,
+//which other processes and virus scanners often hold for a moment
+type windowsPlatform struct{}
+
+func (windowsPlatform) Rename(oldpath, newpath string) error {
+	var err error
+	for i := 0; i < 3; i++ {
+		if err = os.Rename(oldpath, newpath); err == nil || !_sharingError(err) {
+			return err
+		}
+		time.Sleep(time.Millisecond * 20)
+	}
+
+	return err
+}
+
+func _sharingError(err error) bool {
+	var errno syscall.Errno
+	return errors.As(err, &errno) && (errno == errSharingViolation || errno == syscall.ERROR_ACCESS_DENIED)
+}
+
+func (windowsPlatform) CanLock() bool {
+	return true
+}
+
+//lock the whole file with LockFileEx
+func (windowsPlatform) Lock(f *os.File) error {
+	var ol syscall.Overlapped
+	r, _, err := procLockFileEx.Call(f.Fd(), lockfileExclusiveLock, 0, lockAll, lockAll, uintptr(unsafe.Pointer(&ol)))
+	if r == 0 {
+		return err
+	}
+
+	return nil
+}
+
+func (windowsPlatform) Unlock(f *os.File) error {
+	var ol syscall.Overlapped
+	r, _, err := procUnlockFileEx.Call(f.Fd(), 0, lockAll, lockAll, uintptr(unsafe.Pointer(&ol)))
+	if r == 0 {
+		return err
+	}
+
+	return nil
+}
+
+//FlushFileBuffers
+func (windowsPlatform) Sync(f *os.File) error {
+	return f.Sync()
+}
@@ -0,0 +1,79 @@
+package easylog
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+)
+
+//bufferPool recycles entry buffers. shards share the pool of their logger,
+//since buffers move to them with the entries.
+type bufferPool struct {
+	pool  sync.Pool
+	stats *poolStats
+}
+
+type poolStats struct {
+	gets     uint64
+	hits     uint64
+	retained int64
+}
+
+func _newBufferPool() *bufferPool {
+	return &bufferPool{stats: &poolStats{}}
+}
+
+//recycle entry buffers. pooling saves an allocation per entry; disable it to
+//rule out the pool when chasing memory held by a process, or to measure it
+//with BufferStats. if enable is true, buffers are pooled. this is the default.
+func (t *EasyLog) SetBufferPooling(enable bool) error {
+	var v int32
+	if !enable {
+		v = 1
+	}
+
+	atomic.StoreInt32(&t.noPool, v)
+
+	return nil
+}
+
+func (t *EasyLog) GetBufferPooling() bool {
+	return atomic.LoadInt32(&t.noPool) == 0
+}
+
+func (t *EasyLog) _getBuffer() *bytes.Buffer {
+	t = t._owner()
+	p := t.pool
+	atomic.AddUint64(&p.stats.gets, 1)
+	if !t.GetBufferPooling() {
+		return &bytes.Buffer{}
+	}
+
+	if buf, _ := p.pool.Get().(*bytes.Buffer); buf != nil {
+		atomic.AddUint64(&p.stats.hits, 1)
+		atomic.AddInt64(&p.stats.retained, -int64(buf.Cap()))
+		buf.Reset()
+		return buf
+	}
+
+	return &bytes.Buffer{}
+}
+
+func (t *EasyLog) _putBuffer(buf *bytes.Buffer) {
+	//shards recycle the buffers of their logger with its settings
+	t = t._owner()
+	pooled := t.GetBufferPooling()
+	if max := atomic.LoadInt64(&t.maxBufferCap); pooled && max > 0 && int64(buf.Cap()) > max {
+		//let oversized buffers go to the garbage collector
+		atomic.AddUint64(&t.discarded, 1)
+		pooled = false
+	}
+
+	if !pooled {
+		return
+	}
+
+	buf.Reset()
+	atomic.AddInt64(&t.pool.stats.retained, int64(buf.Cap()))
+	t.pool.pool.Put(buf)
+}
@@ -0,0 +1,31 @@
+package easylog
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestPoolRetainedBufferNotReturned(t *testing.T) {
+	l := newTestLog(t, Options{}, realClock{})
+	before := l.BufferStats().PoolRetained
+
+	buf := l._getBuffer()
+	buf.Grow(1024)
+	size := int64(buf.Cap())
+	l._putBuffer(buf)
+	if got := l.BufferStats().PoolRetained; got != before+size {
+		t.Fatalf("retained %d bytes after returning a buffer, want %d", got, before+size)
+	}
+
+	//taken out for good, then freed
+	if l._getBuffer() != buf {
+		t.Skip("the pool dropped the buffer")
+	}
+	buf = nil
+	runtime.GC()
+	runtime.GC()
+
+	if got := l.BufferStats().PoolRetained; got != before {
+		t.Fatalf("retained %d bytes after the buffer was taken out and freed, want %d", got, before)
+	}
+}
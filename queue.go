@@ -0,0 +1,117 @@
+package easylog
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+//what Write does when the write queue is full
+type QueuePolicy int32
+
+const (
+	//wait until the serve goroutine makes room. nothing is lost.
+	Block QueuePolicy = iota
+	//discard the entry being written
+	DropNewest
+	//discard the oldest queued entry to make room for the new one
+	DropOldest
+)
+
+func (p QueuePolicy) String() string {
+	switch p {
+	case Block:
+		return "Block"
+	case DropNewest:
+		return "DropNewest"
+	case DropOldest:
+		return "DropOldest"
+	}
+
+	return fmt.Sprintf("QueuePolicy(%d)", int32(p))
+}
+
+//set how Write behaves when the write queue is full.
+//dropped entries are counted and can be read with Dropped.
+func (t *EasyLog) SetQueuePolicy(policy QueuePolicy) error {
+	if policy < Block || policy > DropOldest {
+		return fmt.Errorf("easylog: invalid queue policy %d", int32(policy))
+	}
+
+	atomic.StoreInt32(&t.queuePolicy, int32(policy))
+
+	return nil
+}
+
+func (t *EasyLog) GetQueuePolicy() QueuePolicy {
+	return QueuePolicy(atomic.LoadInt32(&t.queuePolicy))
+}
+
+//number of entries discarded because the write queue was full
+func (t *EasyLog) Dropped() uint64 {
+	return atomic.LoadUint64(&t.dropped)
+}
+
+//number of entries waiting in the write queue
+func (t *EasyLog) QueueLen() int {
+	return len(t.pipe)
+}
+
+//capacity of the write queue, see Options.BufferLen
+func (t *EasyLog) QueueCap() int {
+	return cap(t.pipe)
+}
+
+type queueWatch struct {
+	limit int
+	fn    func(length, capacity int)
+}
+
+//call fn when the write queue fills beyond threshold, a fraction of its
+//capacity such as 0.8, so the application can turn down verbose logging
+//before entries are dropped or Write blocks. fn runs on its own goroutine,
+//at most once a second. pass a nil fn to remove it.
+func (t *EasyLog) OnQueueHigh(threshold float64, fn func(length, capacity int)) error {
+	if fn == nil {
+		t.queueWatch.Store(queueWatch{})
+		return nil
+	}
+
+	if threshold <= 0 || threshold > 1 {
+		return fmt.Errorf("easylog: queue threshold %v must be in (0, 1]", threshold)
+	}
+
+	limit := int(threshold * float64(cap(t.pipe)))
+	if limit < 1 {
+		limit = 1
+	}
+
+	atomic.StoreInt64(&t.queueFired, 0)
+	t.queueWatch.Store(queueWatch{limit: limit, fn: fn})
+
+	return nil
+}
+
+//fire the OnQueueHigh callback when the queue is beyond its threshold, at
+//most once a second, so a queue that keeps filling up doesn't flood fn
+func (t *EasyLog) _watchQueue() {
+	w, _ := t.queueWatch.Load().(queueWatch)
+	if w.fn == nil {
+		return
+	}
+
+	n := len(t.pipe)
+	if n < w.limit {
+		return
+	}
+
+	now := t._now().UnixNano()
+	last := atomic.LoadInt64(&t.queueFired)
+	if now-last < int64(time.Second) {
+		return
+	}
+
+	if atomic.CompareAndSwapInt64(&t.queueFired, last, now) {
+		go w.fn(n, cap(t.pipe))
+	}
+}
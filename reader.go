@@ -0,0 +1,353 @@
+package easylog
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+//Record is a single line read back from the log files
+type Record struct {
+	//file the line was read from
+	File string
+	//the line without its terminator
+	Line []byte
+	//time and level of the entry, if they could be parsed from the line
+	Time     time.Time
+	HasTime  bool
+	Level    Level
+	HasLevel bool
+}
+
+//Reader iterates over the lines of a logger's current and rotated files, in
+//time order. it understands the rotation naming scheme, .gz compression and
+//encryption, and can filter by time range and level.
+type Reader struct {
+	dir     string
+	name    string
+	pattern string
+
+	from, to   time.Time
+	minLevel   Level
+	byLevel    bool
+	timeFormat string
+	key        []byte
+	separator  string
+	codec      CompressionCodec
+
+	files  []string
+	closer io.Closer
+	scan   *bufio.Scanner
+	rec    Record
+	err    error
+	opened bool
+}
+
+//open the log files written to dir under name. the files are listed when
+//Next is first called, so options can be set before.
+func Open(dir, name string) *Reader {
+	return &Reader{dir: dir, name: name, pattern: defaultRotatePattern}
+}
+
+//set the codec of compressed files, see SetCompressionCodec. .gz files are
+//read either way.
+func (r *Reader) SetCompressionCodec(codec CompressionCodec) *Reader {
+	r.codec = codec
+	return r
+}
+
+//set the pattern used to find rotated files, see SetRotateNamePattern
+func (r *Reader) SetRotateNamePattern(pattern string) *Reader {
+	if pattern != "" {
+		r.pattern = pattern
+	}
+	return r
+}
+
+//only return entries with from <= time < to. a zero value leaves that end
+//open. lines without a parseable time are skipped while a range is set.
+func (r *Reader) SetTimeRange(from, to time.Time) *Reader {
+	r.from, r.to = from, to
+	return r
+}
+
+//only return entries at or above level. lines without a parseable level are
+//skipped while a level is set.
+func (r *Reader) SetMinLevel(level Level) *Reader {
+	r.minLevel, r.byLevel = level, true
+	return r
+}
+
+//set the time layout of TextFormat lines, if SetTimeFormat was used
+func (r *Reader) SetTimeFormat(layout string) *Reader {
+	r.timeFormat = layout
+	return r
+}
+
+//split records by sep, if the logger used SetRecordSeparator
+func (r *Reader) SetRecordSeparator(sep string) *Reader {
+	r.separator = sep
+	return r
+}
+
+//decrypt files written with SetEncryptionKey
+func (r *Reader) SetDecryptionKey(key []byte) *Reader {
+	r.key = key
+	return r
+}
+
+//advance to the next matching record. it returns false at the end of the
+//last file or on error.
+func (r *Reader) Next() bool {
+	if r.err != nil {
+		return false
+	}
+
+	if !r.opened {
+		r.opened = true
+		if r.err = r._listFiles(); r.err != nil {
+			return false
+		}
+	}
+
+	for {
+		if r.scan == nil {
+			if len(r.files) == 0 {
+				return false
+			}
+			if r.err = r._openNext(); r.err != nil {
+				return false
+			}
+			continue
+		}
+
+		if !r.scan.Scan() {
+			r.err = r.scan.Err()
+			r._closeFile()
+			if r.err != nil {
+				return false
+			}
+			continue
+		}
+
+		line := r.scan.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		r.rec = Record{File: r.rec.File, Line: line}
+		r._parse(&r.rec)
+		if r._match(&r.rec) {
+			return true
+		}
+	}
+}
+
+//the current record. Line is only valid until the next call to Next.
+func (r *Reader) Record() Record {
+	return r.rec
+}
+
+//the first error met while reading, if any
+func (r *Reader) Err() error {
+	if r.err == io.EOF {
+		return nil
+	}
+	return r.err
+}
+
+func (r *Reader) Close() error {
+	r.files = nil
+	r._closeFile()
+	return nil
+}
+
+func (r *Reader) _listFiles() error {
+	cfg := fileConfig{dir: r.dir, name: r.name, pattern: r.pattern}
+	if r.codec != nil {
+		cfg.compressExt = r.codec.Extension()
+	}
+	re := _rotatedMatcher(cfg)
+
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return err
+	}
+
+	type fileTime struct {
+		path string
+		mod  time.Time
+	}
+
+	var rotated []fileTime
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == r.name || !re.MatchString(e.Name()) || strings.HasSuffix(e.Name(), ".tmp") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		//a file last written before the range starts can't contain matches
+		if !r.from.IsZero() && info.ModTime().Before(r.from) {
+			continue
+		}
+		rotated = append(rotated, fileTime{filepath.Join(r.dir, e.Name()), info.ModTime()})
+	}
+
+	sort.Slice(rotated, func(i, j int) bool {
+		if !rotated[i].mod.Equal(rotated[j].mod) {
+			return rotated[i].mod.Before(rotated[j].mod)
+		}
+		return rotated[i].path < rotated[j].path
+	})
+
+	for _, f := range rotated {
+		r.files = append(r.files, f.path)
+	}
+
+	current := filepath.Join(r.dir, r.name)
+	if _exists(current) {
+		r.files = append(r.files, current)
+	}
+
+	return nil
+}
+
+func (r *Reader) _openNext() error {
+	path := r.files[0]
+	r.files = r.files[1:]
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	var src io.Reader = f
+	closer := io.Closer(f)
+
+	codec := r.codec
+	if codec == nil || !strings.HasSuffix(path, codec.Extension()) {
+		codec = nil
+		if strings.HasSuffix(path, ".gz") {
+			codec = GzipCodec(gzip.DefaultCompression)
+		}
+	}
+	if codec != nil {
+		zr, err := codec.NewReader(f)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		src = zr
+	}
+
+	if r.key != nil {
+		dr, err := NewDecryptReader(src, r.key)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		src = dr
+	}
+
+	r.closer = closer
+	r.scan = bufio.NewScanner(src)
+	r.scan.Split(_scanRecords(r.separator))
+	r.scan.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	r.rec.File = path
+
+	return nil
+}
+
+func (r *Reader) _closeFile() {
+	if r.closer != nil {
+		r.closer.Close()
+	}
+	r.closer = nil
+	r.scan = nil
+}
+
+func (r *Reader) _match(rec *Record) bool {
+	if !r.from.IsZero() || !r.to.IsZero() {
+		if !rec.HasTime {
+			return false
+		}
+		if !r.from.IsZero() && rec.Time.Before(r.from) {
+			return false
+		}
+		if !r.to.IsZero() && !rec.Time.Before(r.to) {
+			return false
+		}
+	}
+
+	if r.byLevel && (!rec.HasLevel || rec.Level < r.minLevel) {
+		return false
+	}
+
+	return true
+}
+
+//fill in time and level from a JSON, logfmt or text line
+func (r *Reader) _parse(rec *Record) {
+	line := rec.Line
+	if _, _, body, ok := _parseAuditHeader(line); ok {
+		line = body
+	}
+
+	switch {
+	case bytes.HasPrefix(line, []byte("{")):
+		var v struct {
+			Time  string `json:"time"`
+			Level string `json:"level"`
+		}
+		if json.Unmarshal(line, &v) != nil {
+			return
+		}
+		r._setTime(rec, v.Time, time.RFC3339Nano)
+		r._setLevel(rec, v.Level)
+
+	case bytes.HasPrefix(line, []byte("time=")):
+		for _, kv := range strings.Fields(string(line)) {
+			if strings.HasPrefix(kv, "time=") {
+				r._setTime(rec, strings.Trim(kv[5:], `"`), time.RFC3339Nano)
+			} else if strings.HasPrefix(kv, "level=") {
+				r._setLevel(rec, kv[6:])
+				break
+			}
+		}
+
+	default:
+		//<time> [LEVEL] message
+		start := bytes.IndexByte(line, '[')
+		end := bytes.IndexByte(line, ']')
+		if start <= 0 || end < start {
+			return
+		}
+		r._setTime(rec, strings.TrimSpace(string(line[:start])), "2006-01-02 15:04:05.000")
+		r._setLevel(rec, string(line[start+1:end]))
+	}
+}
+
+func (r *Reader) _setTime(rec *Record, value, layout string) {
+	if r.timeFormat != "" {
+		layout = r.timeFormat
+	}
+
+	if tm, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+		rec.Time, rec.HasTime = tm, true
+	}
+}
+
+func (r *Reader) _setLevel(rec *Record, value string) {
+	if level, err := ParseLevel(value); err == nil {
+		rec.Level, rec.HasLevel = level, true
+	}
+}
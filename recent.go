@@ -0,0 +1,116 @@
+package easylog
+
+import (
+	"bytes"
+	"sync"
+)
+
+//the last entries written, kept in memory by SetRecentSize
+type ring struct {
+	mu      sync.Mutex
+	entries [][]byte
+	next    int
+	count   int
+}
+
+//keep the last n entries in memory, alongside the file, so they can be
+//included in crash reports or served by AdminHandler's /recent endpoint.
+//entries are kept as written to the file, once they are flushed.
+//if n == 0, no entries are kept. this is the default.
+func (t *EasyLog) SetRecentSize(n int) error {
+	if n < 0 {
+		n = 0
+	}
+
+	t.recent.resize(n)
+
+	return nil
+}
+
+func (t *EasyLog) GetRecentSize() int {
+	t.recent.mu.Lock()
+	defer t.recent.mu.Unlock()
+
+	return len(t.recent.entries)
+}
+
+//return copies of the entries kept by SetRecentSize, oldest first
+func (t *EasyLog) Recent() [][]byte {
+	return t.recent.list()
+}
+
+//keep the entries of a flushed batch. only called from the serve goroutine.
+func (t *EasyLog) _keepRecent(data []byte) {
+	r := &t._owner().recent
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.entries) == 0 {
+		return
+	}
+
+	_scanEntries(data, t._sepByte(), func(entry []byte) {
+		r.entries[r.next] = append(r.entries[r.next][:0], entry...)
+		r.next = (r.next + 1) % len(r.entries)
+		if r.count < len(r.entries) {
+			r.count++
+		}
+	})
+}
+
+//call fn for each separator-terminated entry of data, and for trailing
+//bytes without a separator
+func _scanEntries(data []byte, sep byte, fn func(entry []byte)) {
+	for len(data) > 0 {
+		n := bytes.IndexByte(data, sep) + 1
+		if n == 0 {
+			n = len(data)
+		}
+		fn(data[:n])
+		data = data[n:]
+	}
+}
+
+func (r *ring) resize(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r._ordered()
+	if len(kept) > n {
+		kept = kept[len(kept)-n:]
+	}
+
+	r.entries = make([][]byte, n)
+	copy(r.entries, kept)
+	r.count = len(kept)
+	r.next = 0
+	if n > 0 {
+		r.next = r.count % n
+	}
+}
+
+func (r *ring) list() [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list := r._ordered()
+	for i, e := range list {
+		list[i] = append([]byte(nil), e...)
+	}
+
+	return list
+}
+
+//the kept entries, oldest first, sharing the ring's memory
+func (r *ring) _ordered() [][]byte {
+	list := make([][]byte, 0, r.count)
+	start := r.next - r.count
+	if start < 0 {
+		start += len(r.entries)
+	}
+	for i := 0; i < r.count; i++ {
+		list = append(list, r.entries[(start+i)%len(r.entries)])
+	}
+
+	return list
+}
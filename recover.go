@@ -0,0 +1,109 @@
+package easylog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//repair what an earlier run left behind in the log directory, once per
+//active file path: a file beyond the max size, e.g. after a crash during
+//rotation, is rotated, and rotated files whose compression or upload was
+//interrupted are processed again. it runs on the first flush tick or write,
+//whichever comes first, so the settings applied at construction are in
+//effect. only called from the serve goroutine, with the file lock held.
+func (t *EasyLog) _recover(cfg fileConfig) {
+	path := filepath.Join(cfg.dir, cfg.name)
+	if t.recovered == path {
+		return
+	}
+	t.recovered = path
+
+	t._repairRotated(cfg)
+
+	if info, err := os.Stat(path); err == nil && info.Size() > cfg.maxSize {
+		t._afterRotate(t._rename(cfg, t._now()))
+	}
+}
+
+//run _recover from the flush tick
+func (t *EasyLog) _recoverIdle() {
+	cfg := t._fileConfig()
+	if t.sink != nil || t.recovered == filepath.Join(cfg.dir, cfg.name) {
+		return
+	}
+
+	unlock, err := t._lock(cfg)
+	if err != nil {
+		t._reportError(err)
+		return
+	}
+	defer unlock()
+
+	t._recover(cfg)
+}
+
+//remove the temporary files of interrupted compressions and manifest
+//updates, and queue the rotated files still to be compressed or uploaded
+func (t *EasyLog) _repairRotated(cfg fileConfig) {
+	entries, err := os.ReadDir(cfg.dir)
+	if err != nil {
+		return
+	}
+
+	re := _rotatedMatcher(cfg)
+	exts := []string{".gz"}
+	if cfg.compressExt != "" && cfg.compressExt != ".gz" {
+		exts = append(exts, cfg.compressExt)
+	}
+	compressed := func(name string) bool {
+		for _, ext := range exts {
+			if strings.HasSuffix(name, ext) {
+				return true
+			}
+		}
+		return false
+	}
+	manifest := filepath.Base(_manifestPath(cfg))
+	archive := t._archiveConfig()
+
+	for _, e := range entries {
+		name := e.Name()
+		path := filepath.Join(cfg.dir, name)
+		if e.IsDir() {
+			continue
+		}
+
+		if tmp := strings.TrimSuffix(name, ".tmp"); tmp != name {
+			if re.MatchString(tmp) && compressed(tmp) || tmp == manifest {
+				t._reportError(os.Remove(path))
+			}
+			continue
+		}
+
+		if !re.MatchString(name) {
+			continue
+		}
+
+		if !compressed(name) {
+			//the compressed copy is renamed into place only once complete
+			done := false
+			for _, ext := range exts {
+				done = done || _exists(path+ext)
+			}
+			if done {
+				t._reportError(os.Remove(path))
+				continue
+			}
+			if t.GetCompressRotated() || archive.archiver != nil && archive.removeLocal {
+				t._queueRotated(rotatedFile{path: path})
+			}
+			continue
+		}
+
+		//local files are only kept until they are uploaded
+		if archive.archiver != nil && archive.removeLocal {
+			t._queueRotated(rotatedFile{path: path})
+		}
+	}
+}
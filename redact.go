@@ -0,0 +1,96 @@
+package easylog
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+)
+
+//patterns for common sensitive data, for use with AddRedactPattern
+var (
+	//13 to 19 digit card numbers, optionally grouped by spaces or dashes
+	CreditCardPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	EmailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	//bearer tokens in authorization headers
+	BearerTokenPattern = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`)
+)
+
+//Redactor rewrites an encoded entry before it is written, e.g. to mask
+//secrets. the input is the whole entry as written, including the newline.
+type Redactor func(entry string) string
+
+//add a redactor run on every entry, in the order redactors were added.
+//redactors see leveled entries after encoding and data passed to Write,
+//so nothing reaches the log file, the outputs or the console unscrubbed.
+func (t *EasyLog) AddRedactor(r Redactor) error {
+	if r == nil {
+		return nil
+	}
+
+	t.redactMu.Lock()
+	defer t.redactMu.Unlock()
+
+	list := t._redactors()
+	updated := make([]Redactor, 0, len(list)+1)
+	updated = append(updated, list...)
+	t.redactList.Store(append(updated, r))
+
+	return nil
+}
+
+//replace every match of re with repl, which may refer to submatches as in
+//regexp.ReplaceAllString
+func (t *EasyLog) AddRedactPattern(re *regexp.Regexp, repl string) error {
+	if re == nil {
+		return fmt.Errorf("easylog: nil redact pattern")
+	}
+
+	return t.AddRedactor(func(entry string) string {
+		return re.ReplaceAllString(entry, repl)
+	})
+}
+
+//remove all redactors
+func (t *EasyLog) ClearRedactors() {
+	t.redactMu.Lock()
+	t.redactList.Store([]Redactor(nil))
+	t.redactMu.Unlock()
+}
+
+func (t *EasyLog) _redactors() []Redactor {
+	list, _ := t.redactList.Load().([]Redactor)
+	return list
+}
+
+//scrub the entry in buf in place
+func (t *EasyLog) _redact(buf *bytes.Buffer) {
+	t._redactTail(buf, 0)
+}
+
+//redact the entry written to buf from start
+func (t *EasyLog) _redactTail(buf *bytes.Buffer, start int) {
+	list := t._redactors()
+	if len(list) == 0 {
+		return
+	}
+
+	entry := string(buf.Bytes()[start:])
+	for _, r := range list {
+		entry = t._runRedactor(r, entry)
+	}
+
+	buf.Truncate(start)
+	buf.WriteString(entry)
+}
+
+func (t *EasyLog) _runRedactor(r Redactor, entry string) (out string) {
+	defer func() {
+		if v := recover(); v != nil {
+			//drop the entry rather than write what the redactor should hide
+			t._reportError(fmt.Errorf("easylog: redactor panic: %v", v))
+			out = ""
+		}
+	}()
+
+	return r(entry)
+}
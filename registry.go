@@ -0,0 +1,60 @@
+package easylog
+
+import "sync"
+
+var registry = struct {
+	sync.Mutex
+	defaults Options
+	loggers  map[string]*EasyLog
+}{loggers: map[string]*EasyLog{}}
+
+//set the options inherited by loggers created later by GetLogger.
+//loggers that already exist are not changed.
+func SetDefaultOptions(opts Options) {
+	registry.Lock()
+	registry.defaults = opts
+	registry.Unlock()
+}
+
+//return the logger registered under name, creating it on first use from the
+//default options. each named logger writes to its own file, name + ".log",
+//in the default directory, so libraries in one process can keep separate
+//logs without wiring up EasyLog objects by hand.
+func GetLogger(name string) (*EasyLog, error) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if l, ok := registry.loggers[name]; ok {
+		return l, nil
+	}
+
+	opts := registry.defaults
+	opts.FileName = name + ".log"
+
+	l, err := NewLogWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	registry.loggers[name] = l
+
+	return l, nil
+}
+
+//close every logger created by GetLogger and clear the registry.
+//it returns the first error reported by Close.
+func CloseLoggers() error {
+	registry.Lock()
+	loggers := registry.loggers
+	registry.loggers = map[string]*EasyLog{}
+	registry.Unlock()
+
+	var first error
+	for _, l := range loggers {
+		if err := l.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
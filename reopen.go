@@ -0,0 +1,115 @@
+package easylog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultFileCheck = time.Second
+
+//write pending entries, then reopen the log file by name. call it after an
+//external tool such as logrotate moved the file away, so later entries go to
+//a fresh file at the configured path.
+func (t *EasyLog) Reopen() error {
+	return t._do(func(data *bytes.Buffer) error {
+		t._drain(data)
+		err := t._flush(data)
+		if cerr := t._closeSink(); err == nil {
+			err = cerr
+		}
+
+		//the file at the path may be a different one now, so forget the
+		//rotation period derived from the old file. the next flush opens
+		//the path again and creates the file if needed.
+		t.period = time.Time{}
+
+		return err
+	})
+}
+
+//call Reopen whenever one of sigs is received. with no arguments, SIGHUP is
+//handled as expected by logrotate's postrotate scripts, on platforms that
+//have it; elsewhere no signal is handled. handling stops when the logger is
+//closed or the returned function is called.
+func (t *EasyLog) ReopenOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = reopenSignals
+	}
+	if len(sigs) == 0 {
+		return func() {}
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		defer signal.Stop(ch)
+
+		for {
+			select {
+			case <-ch:
+				if err := t.Reopen(); err != nil && err != ErrClosed {
+					t._reportError(err)
+				}
+			case <-done:
+				return
+			case <-t.quit:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+//set how often the active file is checked to still be at its path. if an
+//operator or another tool deleted or moved it, the file is closed and the
+//next write creates a new one at the path, as after Reopen, so entries don't
+//go to an unlinked file. if d == 0, the file is not checked. the default is
+//one second.
+func (t *EasyLog) SetFileCheckInterval(d time.Duration) error {
+	if d < 0 {
+		d = 0
+	}
+
+	atomic.StoreInt64(&t.fileCheck, int64(d))
+
+	return nil
+}
+
+func (t *EasyLog) GetFileCheckInterval() time.Duration {
+	return time.Duration(atomic.LoadInt64(&t.fileCheck))
+}
+
+//close the active file if the path no longer leads to it.
+//only called from the serve goroutine.
+func (t *EasyLog) _checkFile(now time.Time) {
+	d := t.GetFileCheckInterval()
+	if t.file == nil || d <= 0 || now.Sub(t.lastFileCheck) < d {
+		return
+	}
+	t.lastFileCheck = now
+
+	open, err := t.file.Stat()
+	if err != nil {
+		return
+	}
+
+	cur, err := os.Stat(t.filePath)
+	if err == nil && os.SameFile(open, cur) || err != nil && !os.IsNotExist(err) {
+		return
+	}
+
+	t._reportError(fmt.Errorf("easylog: %s was removed or replaced, reopening", t.filePath))
+	t._closeFile()
+	t.period = time.Time{}
+}
@@ -0,0 +1,66 @@
+package easylog
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+const defaultRetryBuffer = 4 * 1024 * 1024
+
+//set how many bytes of batches that failed to write, e.g. because the log
+//file can't be opened, are kept in memory and written again on later
+//flushes, before the batches that follow them. batches that don't fit are
+//dropped, counted in Stats.Dropped and reported to the OnError func.
+//if RetryBuffer == 0, failed batches are dropped at once. the default is 4MB.
+func (t *EasyLog) SetRetryBuffer(RetryBuffer int64) error {
+	if RetryBuffer < 0 {
+		RetryBuffer = 0
+	}
+
+	atomic.StoreInt64(&t.retryMax, RetryBuffer)
+
+	return nil
+}
+
+func (t *EasyLog) GetRetryBuffer() int64 {
+	return atomic.LoadInt64(&t.retryMax)
+}
+
+//write batches that failed earlier, then p. what isn't written is kept for
+//the next flush. only called from the serve goroutine.
+func (t *EasyLog) _writeRetrying(p []byte) error {
+	if t.retryBuf.Len() > 0 {
+		n, err := t._sinkWrite(t.retryBuf.Bytes())
+		t.retryBuf.Next(n)
+		if err != nil {
+			t._keep(p)
+			return err
+		}
+		t.retryBuf.Reset()
+	}
+
+	if len(p) == 0 {
+		return nil
+	}
+
+	n, err := t._sinkWrite(p)
+	if err != nil {
+		t._keep(p[n:])
+	}
+
+	return err
+}
+
+func (t *EasyLog) _keep(p []byte) {
+	if len(p) == 0 {
+		return
+	}
+
+	if int64(t.retryBuf.Len()+len(p)) > t.GetRetryBuffer() {
+		atomic.AddUint64(&t.dropped, 1)
+		t._reportError(fmt.Errorf("easylog: retry buffer full, %d bytes lost", len(p)))
+		return
+	}
+
+	t.retryBuf.Write(p)
+}
@@ -0,0 +1,267 @@
+package easylog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+//set time based rotation. the log file is rotated when a write happens in a
+//new period, e.g. time.Hour rotates at every hour boundary and 24*time.Hour
+//at local midnight. rotated files are named after the start of their period.
+//if d == 0, only size based rotation is done.
+func (t *EasyLog) SetRotateInterval(d time.Duration) error {
+	if d < 0 {
+		d = 0
+	}
+
+	if d > 0 && d < time.Minute {
+		d = time.Minute
+	}
+
+	atomic.StoreInt64(&t.rotateEvery, int64(d))
+
+	return nil
+}
+
+func (t *EasyLog) GetRotateInterval() time.Duration {
+	return time.Duration(atomic.LoadInt64(&t.rotateEvery))
+}
+
+//rotate the log file now, independent of size and time thresholds. pending
+//entries are written to the current file first. nothing is rotated if the
+//current file is missing or empty. with SetSink, the sink's Rotate is called.
+//the files of SetShards are rotated too.
+func (t *EasyLog) Rotate() error {
+	err := t._do(func(data *bytes.Buffer) error {
+		t._drain(data)
+		if err := t._flush(data); err != nil {
+			return err
+		}
+
+		return t._sink().Rotate()
+	})
+
+	for _, child := range t._shardLogs() {
+		if cerr := child.Rotate(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+//start of the period containing tm, aligned to local time
+func _periodStart(tm time.Time, d time.Duration) time.Time {
+	_, offset := tm.Zone()
+	shift := time.Duration(offset) * time.Second
+
+	return tm.Add(shift).Truncate(d).Add(-shift)
+}
+
+//rotate the current file if it belongs to an earlier period.
+//only called from the serve goroutine.
+func (t *EasyLog) _checkPeriod(cfg fileConfig) {
+	d := t.GetRotateInterval()
+	if d <= 0 {
+		t.period = time.Time{}
+		return
+	}
+
+	fullPath := filepath.Join(cfg.dir, cfg.name)
+	now := _periodStart(t._now(), d)
+
+	if t.period.IsZero() || t.periodLen != d {
+		//the existing file may have been written by an earlier run
+		t.period = now
+		t.periodLen = d
+		if info, err := os.Stat(fullPath); err == nil {
+			t.period = _periodStart(info.ModTime(), d)
+		}
+	}
+
+	if t.period.Equal(now) {
+		return
+	}
+
+	//skip a file already rotated and written to by another process
+	if info, err := os.Stat(fullPath); err == nil && info.Size() > 0 && _periodStart(info.ModTime(), d).Before(now) {
+		t._afterRotate(t._rename(cfg, t.period))
+	}
+
+	t.period = now
+}
+
+const defaultRotatePattern = "{name}.{date}"
+
+//set the name of rotated files. the pattern may use these variables:
+//  {name}  the log file's name
+//  {date}  the rotation time as YYYYMMDDHHMMSS
+//  {datems} the rotation time with milliseconds, YYYYMMDDHHMMSS.mmm
+//  {seq}   the smallest number >= 1 that gives an unused name
+//  {pid}   the process id
+//the pattern must contain {date}, {datems} or {seq}. if the expanded name is
+//taken anyway, e.g. by two rotations in the same second, ".1", ".2" and so
+//on is appended, so a rotation never replaces an earlier file.
+//the default pattern is "{name}.{date}".
+func (t *EasyLog) SetRotateNamePattern(pattern string) error {
+	if pattern == "" {
+		pattern = defaultRotatePattern
+	}
+
+	if !strings.Contains(pattern, "{date}") && !strings.Contains(pattern, "{datems}") && !strings.Contains(pattern, "{seq}") {
+		return fmt.Errorf("easylog: rotate name pattern %q needs {date}, {datems} or {seq}", pattern)
+	}
+
+	if strings.ContainsAny(pattern, `/\`) {
+		return fmt.Errorf("easylog: rotate name pattern %q must not contain a path separator", pattern)
+	}
+
+	t.mu.Lock()
+	t.rotatePattern = pattern
+	t.mu.Unlock()
+	t.nofityDelFile()
+
+	return nil
+}
+
+func (t *EasyLog) GetRotateNamePattern() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.rotatePattern
+}
+
+func _expandPattern(cfg fileConfig, tm time.Time, seq int) string {
+	r := strings.NewReplacer(
+		"{name}", cfg.name,
+		"{date}", tm.Format("20060102150405"),
+		"{datems}", tm.Format("20060102150405.000"),
+		"{seq}", strconv.Itoa(seq),
+		"{pid}", strconv.Itoa(os.Getpid()),
+	)
+
+	return r.Replace(cfg.pattern)
+}
+
+//name of the file the active log is renamed to when rotated at tm
+func _rotatedName(cfg fileConfig, tm time.Time) string {
+	taken := func(name string) bool {
+		return _exists(filepath.Join(cfg.dir, name)) || _exists(filepath.Join(cfg.dir, name+".gz")) ||
+			cfg.compressExt != "" && _exists(filepath.Join(cfg.dir, name+cfg.compressExt))
+	}
+
+	if strings.Contains(cfg.pattern, "{seq}") {
+		for seq := 1; ; seq++ {
+			if name := _expandPattern(cfg, tm, seq); !taken(name) {
+				return name
+			}
+		}
+	}
+
+	name := _expandPattern(cfg, tm, 0)
+	if !taken(name) {
+		return name
+	}
+
+	for n := 1; ; n++ {
+		if alt := name + "." + strconv.Itoa(n); !taken(alt) {
+			return alt
+		}
+	}
+}
+
+//regexp matching the whole names of files rotated with cfg's pattern,
+//compressed or not. nothing else in the directory matches.
+func _rotatedMatcher(cfg fileConfig) *regexp.Regexp {
+	vars := map[string]string{
+		"{name}":   regexp.QuoteMeta(cfg.name),
+		"{date}":   `\d{14}`,
+		"{datems}": `\d{14}\.\d{3}`,
+		"{seq}":    `\d+`,
+		"{pid}":    `\d+`,
+	}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	rest := cfg.pattern
+	for len(rest) > 0 {
+		i := strings.IndexByte(rest, '{')
+		if i < 0 {
+			expr.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+
+		expr.WriteString(regexp.QuoteMeta(rest[:i]))
+		rest = rest[i:]
+
+		matched := false
+		for v, sub := range vars {
+			if strings.HasPrefix(rest, v) {
+				expr.WriteString(sub)
+				rest = rest[len(v):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			expr.WriteString(regexp.QuoteMeta("{"))
+			rest = rest[1:]
+		}
+	}
+
+	//names taken by an earlier rotation get a numeric suffix. files
+	//compressed before a codec change still count.
+	expr.WriteString(`(\.\d+)?(\.gz`)
+	if cfg.compressExt != "" {
+		expr.WriteString("|" + regexp.QuoteMeta(cfg.compressExt))
+	}
+	expr.WriteString(`)?$`)
+
+	return regexp.MustCompile(expr.String())
+}
+
+//copy oldpath to newpath, then truncate oldpath to zero length
+func _copyTruncate(fs platform, oldpath, newpath string) error {
+	src, err := os.Open(oldpath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(newpath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, src)
+	if err == nil {
+		err = fs.Sync(dst)
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(newpath)
+		return err
+	}
+
+	return os.Truncate(oldpath, 0)
+}
+
+func _exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,38 @@
+package easylog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotateInterval(t *testing.T) {
+	start := time.Now()
+	c := newFakeClock(start)
+	l := newTestLog(t, Options{RotateInterval: time.Hour}, c)
+	dir, name := l.GetDir()
+
+	l.Write([]byte("hour one"))
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Advance(time.Hour)
+	l.Write([]byte("hour two"))
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := filepath.Join(dir, name+"."+_periodStart(start, time.Hour).Format("20060102150405"))
+	b, err := os.ReadFile(rotated)
+	if err != nil {
+		t.Fatalf("rotated file: %v", err)
+	}
+	if string(b) != "hour one\n" {
+		t.Fatalf("rotated file holds %q, want %q", b, "hour one\n")
+	}
+	if got := readLog(t, l); got != "hour two\n" {
+		t.Fatalf("active file holds %q, want %q", got, "hour two\n")
+	}
+}
@@ -0,0 +1,61 @@
+package easylog
+
+type levelRoute struct {
+	min    Level
+	target *EasyLog
+}
+
+//send leveled entries at or above min to target in addition to this logger.
+//target is an independent EasyLog with its own directory, file name, format
+//and rotation settings, e.g. an error.log next to app.log. target still
+//applies its own level filter. entries are not routed any further from
+//target, and closing this logger does not close target.
+func (t *EasyLog) AddLevelRoute(min Level, target *EasyLog) error {
+	if target == nil || target == t {
+		return nil
+	}
+
+	t.routeMu.Lock()
+	defer t.routeMu.Unlock()
+
+	routes, _ := t.routes.Load().([]levelRoute)
+	updated := make([]levelRoute, 0, len(routes)+1)
+	updated = append(updated, routes...)
+	updated = append(updated, levelRoute{min: min, target: target})
+	t.routes.Store(updated)
+
+	return nil
+}
+
+//stop routing entries to target
+func (t *EasyLog) RemoveLevelRoute(target *EasyLog) error {
+	t.routeMu.Lock()
+	defer t.routeMu.Unlock()
+
+	routes, _ := t.routes.Load().([]levelRoute)
+	updated := make([]levelRoute, 0, len(routes))
+	for _, r := range routes {
+		if r.target != target {
+			updated = append(updated, r)
+		}
+	}
+	t.routes.Store(updated)
+
+	return nil
+}
+
+func (t *EasyLog) _routesFor(level Level) []*EasyLog {
+	routes, _ := t.routes.Load().([]levelRoute)
+	if len(routes) == 0 {
+		return nil
+	}
+
+	targets := make([]*EasyLog, 0, len(routes))
+	for _, r := range routes {
+		if level >= r.min {
+			targets = append(targets, r.target)
+		}
+	}
+
+	return targets
+}
@@ -0,0 +1,125 @@
+package easylog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+//sampler limits identical leveled messages per interval. within each
+//interval the first `initial` occurrences are logged, then every
+//`thereafter`-th one. at the end of an interval a summary entry reports how
+//many duplicates were suppressed.
+type sampler struct {
+	initial    int
+	thereafter int
+	interval   time.Duration
+
+	mu       sync.Mutex
+	start    time.Time
+	counts   map[sampleKey]*sampleCount
+	reported bool
+}
+
+type sampleKey struct {
+	level Level
+	msg   string
+}
+
+type sampleCount struct {
+	seen       int
+	suppressed int
+}
+
+//limit repetitive leveled messages. for every distinct level and message,
+//the first initial occurrences per interval are logged, then every
+//thereafter-th one (thereafter == 0 drops the rest). a "suppressed N
+//duplicates" entry is logged when the interval ends. Write is not sampled.
+//if initial <= 0, sampling is disabled.
+func (t *EasyLog) SetSampling(initial, thereafter int, interval time.Duration) error {
+	if initial <= 0 {
+		t.sampling.Store((*sampler)(nil))
+		return nil
+	}
+
+	if thereafter < 0 {
+		thereafter = 0
+	}
+
+	if interval < time.Millisecond*10 {
+		interval = time.Second
+	}
+
+	t.sampling.Store(&sampler{
+		initial:    initial,
+		thereafter: thereafter,
+		interval:   interval,
+		counts:     map[sampleKey]*sampleCount{},
+	})
+
+	return nil
+}
+
+func (t *EasyLog) _sampler() *sampler {
+	s, _ := t.sampling.Load().(*sampler)
+	return s
+}
+
+func (s *sampler) allow(t *EasyLog, now time.Time, level Level, msg string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.start.IsZero() || now.Sub(s.start) >= s.interval {
+		s._rollover(t)
+		s.start = now
+	}
+
+	key := sampleKey{level: level, msg: msg}
+	c := s.counts[key]
+	if c == nil {
+		c = &sampleCount{}
+		s.counts[key] = c
+	}
+	c.seen++
+
+	if c.seen <= s.initial {
+		return true
+	}
+
+	if s.thereafter > 0 && (c.seen-s.initial)%s.thereafter == 0 {
+		return true
+	}
+
+	c.suppressed++
+
+	if !s.reported {
+		//make sure the summary shows up even if nothing else is logged
+		s.reported = true
+		start := s.start
+		t.clock.AfterFunc(s.interval-now.Sub(start), func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.start.Equal(start) {
+				s._rollover(t)
+				s.start = time.Time{}
+			}
+		})
+	}
+
+	return false
+}
+
+//log summaries for the ending interval and reset the counters.
+//must be called with s.mu held.
+func (s *sampler) _rollover(t *EasyLog) {
+	now := t._now()
+	for key, c := range s.counts {
+		if c.suppressed > 0 {
+			msg := fmt.Sprintf("suppressed %d duplicates of %q", c.suppressed, key.msg)
+			t._dispatch(now, key.level, msg, nil)
+		}
+	}
+
+	s.counts = map[sampleKey]*sampleCount{}
+	s.reported = false
+}
@@ -0,0 +1,299 @@
+package easylog
+
+import (
+	"sync"
+	"time"
+)
+
+//Scheduler runs the background work of many loggers on a fixed number of
+//goroutines and a single timer. without one, every logger keeps goroutines
+//for cleanup, compression and level callbacks, plus its own tickers. with
+//one, each logger only keeps its serve goroutine, which owns the log file,
+//so hundreds of per-tenant loggers stay cheap. pass it in Options.Scheduler
+//and close it after the loggers using it.
+type Scheduler struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []func()
+	tickers map[*schedTicker]struct{}
+	closed  bool
+	clock   clock
+
+	poke chan struct{}
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+//start a scheduler with the given number of workers, at least 1
+func NewScheduler(workers int) *Scheduler {
+	return _newScheduler(workers, realClock{})
+}
+
+//start a scheduler whose tickers follow c
+func _newScheduler(workers int, c clock) *Scheduler {
+	if workers < 1 {
+		workers = 1
+	}
+
+	s := &Scheduler{
+		clock:   c,
+		tickers: map[*schedTicker]struct{}{},
+		poke:    make(chan struct{}, 1),
+		quit:    make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+
+	s.wg.Add(workers + 1)
+	for i := 0; i < workers; i++ {
+		go s._work()
+	}
+	go s._runTimers()
+
+	return s
+}
+
+//stop the workers once queued work is done. work handed over later runs on
+//a goroutine of its own.
+func (s *Scheduler) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrClosed
+	}
+	s.closed = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	close(s.quit)
+	s.wg.Wait()
+
+	return nil
+}
+
+//queue fn for a worker. the queue is unbounded, so work that hands over more
+//work never deadlocks the pool.
+func (s *Scheduler) _submit(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		go fn()
+		return
+	}
+
+	s.queue = append(s.queue, fn)
+	s.cond.Signal()
+}
+
+func (s *Scheduler) _work() {
+	defer s.wg.Done()
+
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if len(s.queue) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		fn := s.queue[0]
+		s.queue[0] = nil
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		fn()
+	}
+}
+
+//one timer serves every ticker, it is set to the earliest tick due
+func (s *Scheduler) _runTimers() {
+	defer s.wg.Done()
+
+	tm := s.clock.NewTimer(time.Hour)
+	defer tm.Stop()
+
+	for {
+		s.mu.Lock()
+		now := s.clock.Now()
+		wait := time.Hour
+		for tk := range s.tickers {
+			if !now.Before(tk.next) {
+				select {
+				case tk.c <- now:
+				default:
+				}
+				tk.next = now.Add(tk.d)
+			}
+			if d := tk.next.Sub(now); d < wait {
+				wait = d
+			}
+		}
+		s.mu.Unlock()
+
+		if !tm.Stop() {
+			select {
+			case <-tm.C():
+			default:
+			}
+		}
+		tm.Reset(wait)
+
+		select {
+		case <-tm.C():
+		case <-s.poke:
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+//schedClock is the clock of loggers using a Scheduler
+type schedClock struct {
+	s *Scheduler
+}
+
+func (c schedClock) Now() time.Time {
+	return c.s.clock.Now()
+}
+
+//timers are rare, they don't share the scheduler's timer
+func (c schedClock) NewTimer(d time.Duration) timer {
+	return c.s.clock.NewTimer(d)
+}
+
+func (c schedClock) AfterFunc(d time.Duration, fn func()) timer {
+	return c.s.clock.AfterFunc(d, fn)
+}
+
+func (c schedClock) NewTicker(d time.Duration) ticker {
+	tk := &schedTicker{s: c.s, c: make(chan time.Time, 1), d: d, next: c.s.clock.Now().Add(d)}
+
+	c.s.mu.Lock()
+	c.s.tickers[tk] = struct{}{}
+	c.s.mu.Unlock()
+
+	select {
+	case c.s.poke <- struct{}{}:
+	default:
+	}
+
+	return tk
+}
+
+type schedTicker struct {
+	s    *Scheduler
+	c    chan time.Time
+	d    time.Duration
+	next time.Time
+}
+
+func (tk *schedTicker) C() <-chan time.Time {
+	return tk.c
+}
+
+func (tk *schedTicker) Stop() {
+	tk.s.mu.Lock()
+	delete(tk.s.tickers, tk)
+	tk.s.mu.Unlock()
+}
+
+//a job run on a Scheduler, one run at a time. a wake during a run asks for
+//one more run.
+type serialJob struct {
+	s     *Scheduler
+	run   func()
+	mu    sync.Mutex
+	idle  *sync.Cond
+	state int
+}
+
+const (
+	jobIdle = iota
+	jobQueued
+	jobRerun
+)
+
+func (j *serialJob) wake() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	switch j.state {
+	case jobIdle:
+		j.state = jobQueued
+		j.s._submit(j._loop)
+	case jobQueued:
+		j.state = jobRerun
+	}
+}
+
+func (j *serialJob) _loop() {
+	for {
+		j.run()
+
+		j.mu.Lock()
+		if j.state == jobRerun {
+			j.state = jobQueued
+			j.mu.Unlock()
+			continue
+		}
+		j.state = jobIdle
+		j.idle.Broadcast()
+		j.mu.Unlock()
+		return
+	}
+}
+
+//wait until no run is queued or running
+func (j *serialJob) wait() {
+	j.mu.Lock()
+	for j.state != jobIdle {
+		j.idle.Wait()
+	}
+	j.mu.Unlock()
+}
+
+//start a background job of t that calls run each time wake is called. on
+//its own, the job has a goroutine that runs it once more after stop is
+//closed if a wake is pending, and then closes done. with a Scheduler, each
+//run borrows a pool worker, done is nil, and Close waits for the job.
+func (t *EasyLog) _worker(run func(), stop <-chan struct{}) (wake func(), done chan struct{}) {
+	if t.sched != nil {
+		j := &serialJob{s: t.sched, run: run}
+		j.idle = sync.NewCond(&j.mu)
+		t.jobs = append(t.jobs, j)
+		return j.wake, nil
+	}
+
+	ch := make(chan struct{}, 1)
+	done = make(chan struct{})
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		defer close(done)
+
+		for {
+			select {
+			case <-ch:
+				run()
+			case <-stop:
+				select {
+				case <-ch:
+					run()
+				default:
+				}
+				return
+			}
+		}
+	}()
+
+	wake = func() {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+
+	return wake, done
+}
@@ -0,0 +1,35 @@
+package easylog
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSchedulerTick(t *testing.T) {
+	c := newFakeClock(time.Now())
+	s := _newScheduler(1, c)
+	//registered first, so it closes after the logger
+	t.Cleanup(func() { s.Close() })
+
+	l := newTestLog(t, Options{FlushFreq: time.Second, Scheduler: s}, c)
+	l.Write([]byte("scheduled"))
+	settle(t, l)
+	if got := readLog(t, l); got != "" {
+		t.Fatalf("flushed before the tick: %q", got)
+	}
+
+	//the scheduler passes the tick on from its own goroutine
+	c.Advance(time.Second)
+	deadline := time.Now().Add(10 * time.Second)
+	for readLog(t, l) == "" {
+		if time.Now().After(deadline) {
+			t.Fatal("the tick never flushed the entry")
+		}
+		settle(t, l)
+		runtime.Gosched()
+	}
+	if got := readLog(t, l); got != "scheduled\n" {
+		t.Fatalf("the file holds %q, want %q", got, "scheduled\n")
+	}
+}
@@ -0,0 +1,75 @@
+package easylog
+
+import (
+	"bufio"
+	"bytes"
+)
+
+//set the terminator written after every entry, e.g. "\r\n" or "\x00" for
+//pipelines that split records by NUL. Reader.SetRecordSeparator, the admin
+//tail endpoint and spools added by AddOutput split by it. if sep == "", the default "\n" is used.
+func (t *EasyLog) SetRecordSeparator(sep string) error {
+	if sep == "" {
+		sep = "\n"
+	}
+
+	t.separator.Store(sep)
+
+	t.outMu.Lock()
+	for _, w := range t.outputs {
+		if s, ok := w.(*Spool); ok {
+			s.SetRecordSeparator(sep)
+		}
+	}
+	t.outMu.Unlock()
+
+	return nil
+}
+
+func (t *EasyLog) GetRecordSeparator() string {
+	if sep, _ := t.separator.Load().(string); sep != "" {
+		return sep
+	}
+
+	return "\n"
+}
+
+//replace the newline the encoders end an entry with by the separator
+func (t *EasyLog) _terminate(buf *bytes.Buffer) {
+	sep := t.GetRecordSeparator()
+	if sep == "\n" {
+		return
+	}
+
+	if b := buf.Bytes(); len(b) > 0 && b[len(b)-1] == '\n' {
+		buf.Truncate(len(b) - 1)
+	}
+	buf.WriteString(sep)
+}
+
+//last byte of the separator, where batches may be split between entries
+func (t *EasyLog) _sepByte() byte {
+	sep := t.GetRecordSeparator()
+	return sep[len(sep)-1]
+}
+
+//a bufio.SplitFunc returning the records ended by sep, without sep. a
+//trailing "\r" is dropped from "\n" records, like bufio.ScanLines.
+func _scanRecords(sep string) bufio.SplitFunc {
+	if sep == "" || sep == "\n" {
+		return bufio.ScanLines
+	}
+
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		if i := bytes.Index(data, []byte(sep)); i >= 0 {
+			return i + len(sep), data[:i], nil
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
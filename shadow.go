@@ -0,0 +1,102 @@
+package easylog
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+//holds the logger writing the JSON shadow file, nil if there is none
+type shadowBox struct {
+	log *EasyLog
+}
+
+//write every leveled entry a second time, as JSON, to FileName in the log
+//directory. the text file stays readable for humans and the shadow file
+//serves machines. the shadow file rotates together with the log file and
+//keeps as many rotated files. raw Write data is not copied.
+//if FileName == "", the shadow file is closed.
+func (t *EasyLog) SetJSONShadow(FileName string) error {
+	if strings.ContainsAny(FileName, `/\`) {
+		return fmt.Errorf("easylog: shadow file name %q must not contain a path separator", FileName)
+	}
+
+	cfg := t._fileConfig()
+	if FileName != "" && FileName == cfg.name {
+		return fmt.Errorf("easylog: shadow file name %q is the log file's name", FileName)
+	}
+
+	t.shadowMu.Lock()
+	defer t.shadowMu.Unlock()
+
+	if old := t._shadow(); old != nil {
+		t.shadow.Store(shadowBox{})
+		old.Close()
+	}
+
+	if FileName == "" {
+		return nil
+	}
+
+	dirMode, fileMode := t.GetPermissions()
+	sh, err := _newLogWithClock(Options{
+		Dir:               cfg.root,
+		FileName:          FileName,
+		DailyDirs:         cfg.daily,
+		DirMode:           dirMode,
+		FileMode:          fileMode,
+		MaxFileCount:      cfg.maxCount,
+		RotateNamePattern: cfg.pattern,
+		CompressRotated:   t.GetCompressRotated(),
+		Format:            JSONFormat,
+		Scheduler:         t.sched,
+	}, t.clock)
+	if err != nil {
+		return err
+	}
+
+	//only rotated along with the log file
+	sh.mu.Lock()
+	sh.maxFileSize = math.MaxInt64
+	sh.mu.Unlock()
+
+	t.shadow.Store(shadowBox{log: sh})
+
+	return nil
+}
+
+//get the name of the JSON shadow file, "" if there is none
+func (t *EasyLog) GetJSONShadow() string {
+	sh := t._shadow()
+	if sh == nil {
+		return ""
+	}
+
+	_, name := sh.GetDir()
+
+	return name
+}
+
+func (t *EasyLog) _shadow() *EasyLog {
+	box, _ := t.shadow.Load().(shadowBox)
+	return box.log
+}
+
+//rotate the shadow file after the log file rotated, picking up changes of
+//the directory and retention settings
+func (t *EasyLog) _rotateShadow() {
+	sh := t._shadow()
+	if sh == nil {
+		return
+	}
+
+	cfg := t._fileConfig()
+	_, name := sh.GetDir()
+	sh.SetDir(cfg.root, name)
+	sh.SetDailyDirs(cfg.daily)
+	sh.SetMaxFileCount(cfg.maxCount)
+	sh.SetRotateNamePattern(cfg.pattern)
+	sh.SetCompressRotated(t.GetCompressRotated())
+
+	t._reportError(sh.Rotate())
+}
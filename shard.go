@@ -0,0 +1,136 @@
+package easylog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+var errShardSink = errors.New("easylog: shards can't share the sink set by SetSink")
+
+//the loggers entries are spread over by SetShards
+type shardSet struct {
+	logs []*EasyLog
+	key  string
+	next uint32
+}
+
+//spread entries over n files written by n goroutines, for services logging
+//more than a single writer keeps up with. the files are named after the log
+//file with the shard number, e.g. app-0.log and app-1.log, and take the
+//logger's settings when SetShards is called: rotation, retention, format,
+//level, and what batches are flushed with, such as encryption, separator,
+//encoding, audit mode, sync policy, headers, footers, manifest and archiver.
+//outputs added by AddOutput, the entries kept by SetRecentSize, the buffer
+//pool and OnError stay the logger's, shared by its shards. a sink set by
+//SetSink can't be shared, so SetShards returns an error while one is set,
+//except Discard.
+//entries with a key field go to the shard the hash of its value picks, so
+//e.g. the entries of one request stay in order in one file; other entries
+//and Write payloads are spread round-robin. there is no order between
+//shards; use SetOrderedTimestamps to merge them by time.
+//it should be set at startup: changing it closes the previous shards, and
+//entries routed to them meanwhile are lost. if n <= 1, all entries go to the
+//log file again. this is the default.
+func (t *EasyLog) SetShards(n int, key string) error {
+	t.shardMu.Lock()
+	defer t.shardMu.Unlock()
+
+	var set *shardSet
+	if n > 1 {
+		var shared bool
+		if err := t._do(func(data *bytes.Buffer) error {
+			_, discard := t.sink.(*discarder)
+			shared = t.sink != nil && !discard
+			return nil
+		}); err != nil {
+			return err
+		}
+		if shared {
+			return errShardSink
+		}
+
+		cfg := t._fileConfig()
+		ext := filepath.Ext(cfg.name)
+		base := strings.TrimSuffix(cfg.name, ext)
+
+		set = &shardSet{key: key}
+		for i := 0; i < n; i++ {
+			child, err := t._newChild(cfg.root, fmt.Sprintf("%s-%d%s", base, i, ext))
+			if err != nil {
+				for _, child := range set.logs {
+					child.Close()
+				}
+				return err
+			}
+			//nothing is queued to child yet
+			child.owner = t
+			set.logs = append(set.logs, child)
+		}
+	}
+
+	old := t._shardSet()
+	t.shards.Store(set)
+	if old != nil {
+		for _, child := range old.logs {
+			child.Close()
+		}
+	}
+
+	return nil
+}
+
+func (t *EasyLog) GetShards() int {
+	if set := t._shardSet(); set != nil {
+		return len(set.logs)
+	}
+	return 1
+}
+
+func (t *EasyLog) _shardSet() *shardSet {
+	set, _ := t.shards.Load().(*shardSet)
+	return set
+}
+
+//the logger whose outputs, recent entries and buffer pool t uses: the
+//logger of a shard, t itself otherwise
+func (t *EasyLog) _owner() *EasyLog {
+	if t.owner != nil {
+		return t.owner
+	}
+	return t
+}
+
+//the shard loggers, nil without sharding
+func (t *EasyLog) _shardLogs() []*EasyLog {
+	if set := t._shardSet(); set != nil {
+		return set.logs
+	}
+	return nil
+}
+
+//the logger that queues an entry with fields, t itself without sharding
+func (t *EasyLog) _shard(fields Fields) *EasyLog {
+	set := t._shardSet()
+	if set == nil {
+		return t
+	}
+
+	n := uint32(len(set.logs))
+	if v, ok := fields[set.key]; ok && set.key != "" {
+		h := fnv.New32a()
+		if s, ok := v.(string); ok {
+			io.WriteString(h, s)
+		} else {
+			fmt.Fprint(h, v)
+		}
+		return set.logs[h.Sum32()%n]
+	}
+
+	return set.logs[atomic.AddUint32(&set.next, 1)%n]
+}
@@ -0,0 +1,151 @@
+package easylog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+//Sink stores the data flushed by a logger. the rotating file backend
+//configured with SetDir is the default sink. SetSink replaces it, e.g. with
+//object storage or a database, while batching, redaction, encryption,
+//outputs and the flush loop stay the same. methods are called one at a
+//time from the logger's serve goroutine.
+//
+//a sink that also has a Sync() error method is synced by WriteSync and the
+//sync policy.
+type Sink interface {
+	//prepare for writes. called before the first Write and after Reopen.
+	Open() error
+	//store a batch of entries. p is only valid during the call.
+	Write(p []byte) (n int, err error)
+	//start a new segment, called by EasyLog.Rotate
+	Rotate() error
+	Close() error
+}
+
+//send flushed data to s instead of the log file. pending entries are
+//written to the previous sink first, and it is closed. pass nil to return
+//to the log file.
+func (t *EasyLog) SetSink(s Sink) error {
+	return t._do(func(data *bytes.Buffer) error {
+		t._drain(data)
+		err := t._flush(data)
+		if cerr := t._closeSink(); err == nil {
+			err = cerr
+		}
+
+		t.sink = s
+		if _, ok := s.(discardSink); ok {
+			t.sink = &discarder{t: t}
+		}
+
+		return err
+	})
+}
+
+func (t *EasyLog) _sink() Sink {
+	if t.sink != nil {
+		return t.sink
+	}
+
+	return fileSink{t}
+}
+
+func (t *EasyLog) _sinkWrite(p []byte) (int, error) {
+	s := t._sink()
+	if !t.sinkOpen {
+		if err := s.Open(); err != nil {
+			return 0, err
+		}
+		t.sinkOpen = true
+	}
+
+	return s.Write(p)
+}
+
+func (t *EasyLog) _closeSink() error {
+	open := t.sinkOpen
+	t.sinkOpen = false
+
+	//the file sink may hold files it opened outside Open, e.g. to fsync
+	if !open && t.sink != nil {
+		return nil
+	}
+
+	return t._sink().Close()
+}
+
+//fileSink is the default sink, the rotating log file
+type fileSink struct {
+	t *EasyLog
+}
+
+//with a fallback directory, a file that can't be opened is not an error,
+//writes go to the fallback
+func (s fileSink) Open() error {
+	cfg := s.t._fileConfig()
+	_, err := s.t._openFile(cfg)
+	if cfg.fallbackDir != "" {
+		return nil
+	}
+
+	return err
+}
+
+//on error, n counts the bytes written before a rotation
+func (s fileSink) Write(p []byte) (int, error) {
+	data := bytes.NewBuffer(p)
+	if err := s.t._writeFile(data); err != nil {
+		return len(p) - data.Len(), err
+	}
+
+	return len(p), nil
+}
+
+//nothing is rotated if the current file is missing or empty
+func (s fileSink) Rotate() error {
+	t := s.t
+	cfg := t._fileConfig()
+	unlock, err := t._lock(cfg)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	info, err := os.Stat(filepath.Join(cfg.dir, cfg.name))
+	if err != nil || info.Size() == 0 {
+		return nil
+	}
+
+	newpath := t._rename(cfg, t._now())
+	if newpath == "" {
+		return fmt.Errorf("easylog: rotate %s failed", cfg.name)
+	}
+	t._afterRotate(newpath)
+
+	return nil
+}
+
+func (s fileSink) Sync() error {
+	//syncing must not create a file nothing was written to
+	cfg := s.t._fileConfig()
+	if s.t.file == nil && !_exists(filepath.Join(cfg.dir, cfg.name)) {
+		return nil
+	}
+
+	f, err := s.t._openFile(cfg)
+	if err != nil {
+		return err
+	}
+
+	return s.t.fs.Sync(f)
+}
+
+func (s fileSink) Close() error {
+	err := s.t._closeFile()
+	s.t._closeLock()
+
+	return err
+}
@@ -0,0 +1,114 @@
+//go:build go1.21
+// +build go1.21
+
+package easylog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+)
+
+//SlogHandler adapts an EasyLog to the log/slog front-end. records keep the
+//async buffered write and rotation of the underlying EasyLog, and attributes
+//become entry fields. group names are joined to keys with a dot.
+type SlogHandler struct {
+	log    *EasyLog
+	fields Fields
+	prefix string
+}
+
+//return a slog.Handler that logs through t
+func (t *EasyLog) SlogHandler() *SlogHandler {
+	return &SlogHandler{log: t}
+}
+
+//return a *slog.Logger that logs through t
+func (t *EasyLog) Slog() *slog.Logger {
+	return slog.New(t.SlogHandler())
+}
+
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.log._wanted(_fromSlogLevel(level), h.fields)
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make(Fields, len(h.fields)+r.NumAttrs())
+	for k, v := range FieldsFromContext(ctx) {
+		fields[k] = v
+	}
+	for k, v := range h.fields {
+		fields[k] = v
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		_addAttr(fields, h.prefix, a)
+		return true
+	})
+
+	if h.log.GetReportCaller() && r.PC != 0 {
+		frames := runtime.CallersFrames([]uintptr{r.PC})
+		frame, _ := frames.Next()
+		_addCaller(fields, frame)
+	}
+
+	level := _fromSlogLevel(r.Level)
+	if h.log._wantStack(level) {
+		fields["stack"] = _stacktraceFrom(r.PC, h.log.GetStacktraceDepth())
+	}
+
+	h.log._logMsg(r.Time, level, r.Message, fields)
+
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make(Fields, len(h.fields)+len(attrs))
+	for k, v := range h.fields {
+		fields[k] = v
+	}
+	for _, a := range attrs {
+		_addAttr(fields, h.prefix, a)
+	}
+
+	return &SlogHandler{log: h.log, fields: fields, prefix: h.prefix}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &SlogHandler{log: h.log, fields: h.fields, prefix: h.prefix + name + "."}
+}
+
+func _addAttr(fields Fields, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			_addAttr(fields, prefix, ga)
+		}
+		return
+	}
+
+	fields[prefix+a.Key] = a.Value.Any()
+}
+
+func _fromSlogLevel(level slog.Level) Level {
+	switch {
+	case level >= slog.LevelError:
+		return ErrorLevel
+	case level >= slog.LevelWarn:
+		return WarnLevel
+	case level >= slog.LevelInfo:
+		return InfoLevel
+	}
+
+	return DebugLevel
+}
@@ -0,0 +1,28 @@
+//go:build go1.21
+// +build go1.21
+
+package easylog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStacktraceSlog(t *testing.T) {
+	l := newTestLog(t, Options{Format: JSONFormat}, realClock{})
+	l.SetStacktraceLevel(WarnLevel)
+
+	l.Slog().Warn("slog")
+	l.Slog().Info("below the level")
+
+	stacks := readStacks(t, l)
+	if len(stacks) != 2 {
+		t.Fatalf("got %d entries, want 2", len(stacks))
+	}
+	if top := "github.com/carr123/easylog.TestStacktraceSlog\n\t"; !strings.HasPrefix(stacks[0], top) {
+		t.Errorf("stack starts at %q, want the call site", stacks[0])
+	}
+	if stacks[1] != "" {
+		t.Errorf("entry below the stack trace level has stack %q", stacks[1])
+	}
+}
@@ -0,0 +1,168 @@
+package easylog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+//set a directory, e.g. /tmp, that takes the log data while writing to the
+//primary directory fails, for example with ENOSPC. the spilled file has the
+//configured file name and is not rotated. once the primary directory is
+//writable again, its entries are written to the primary log file before any
+//newer entry, rotating it as usual, and it is removed. pass "" to disable the
+//fallback.
+func (t *EasyLog) SetFallbackDir(szDir string) error {
+	t.mu.RLock()
+	dirMode, lazy := t.dirMode, t.lazyCreate
+	t.mu.RUnlock()
+
+	if szDir != "" && !lazy {
+		if err := os.MkdirAll(szDir, dirMode); err != nil {
+			return err
+		}
+	}
+
+	t.mu.Lock()
+	t.fallbackDir = szDir
+	t.mu.Unlock()
+
+	return nil
+}
+
+func (t *EasyLog) GetFallbackDir() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.fallbackDir
+}
+
+func (t *EasyLog) _writeWithFallback(cfg fileConfig, data *bytes.Buffer) error {
+	spillPath := filepath.Join(cfg.fallbackDir, cfg.name)
+
+	//keep entries in order: spilled data goes back first
+	if _exists(spillPath) {
+		if err := t._catchUp(cfg, spillPath); err != nil {
+			return t._spill(cfg, spillPath, data)
+		}
+	}
+
+	err := t._writePrimary(cfg, data)
+	if err == nil {
+		return nil
+	}
+
+	t._reportError(fmt.Errorf("easylog: write to %s failed, spilling to %s: %w", cfg.dir, cfg.fallbackDir, err))
+
+	if serr := t._spill(cfg, spillPath, data); serr != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (t *EasyLog) _spill(cfg fileConfig, spillPath string, data *bytes.Buffer) error {
+	if cfg.lazy {
+		if err := os.MkdirAll(cfg.fallbackDir, cfg.dirMode); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(spillPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, cfg.fileMode)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data.Bytes())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+//bytes of spilled entries replayed at a time
+const spillChunk = 256 << 10
+
+//append the spilled file to the primary log file and remove it. entries are
+//replayed through _writePrimary a chunk at a time, so the primary file is
+//rotated, gets its headers and is locked like for any other write. a failed
+//replay goes on after the entries already written the next time.
+func (t *EasyLog) _catchUp(cfg fileConfig, spillPath string) error {
+	src, err := os.Open(spillPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if fi, err := src.Stat(); err != nil {
+		return err
+	} else if t.spillOffset > fi.Size() {
+		//the file was replaced
+		t.spillOffset = 0
+	}
+	if _, err := src.Seek(t.spillOffset, io.SeekStart); err != nil {
+		return err
+	}
+
+	r := bufio.NewReaderSize(src, spillChunk)
+	var chunk bytes.Buffer
+	for {
+		chunk.Reset()
+		err := t._readSpillChunk(r, &chunk)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if chunk.Len() == 0 {
+			break
+		}
+
+		n := chunk.Len()
+		werr := t._writePrimary(cfg, &chunk)
+		t.spillOffset += int64(n - chunk.Len())
+		if werr != nil {
+			return werr
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+
+	src.Close()
+	t.spillOffset = 0
+
+	return os.Remove(spillPath)
+}
+
+//read the next whole entries of a spilled file into chunk, about spillChunk
+//bytes of them. an encrypted file is read one sealed batch at a time, since
+//_writePrimary doesn't split those.
+func (t *EasyLog) _readSpillChunk(r *bufio.Reader, chunk *bytes.Buffer) error {
+	if box, _ := t.crypt.Load().(aeadBox); box.aead != nil {
+		var header [4]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = io.EOF
+			}
+			return err
+		}
+		chunk.Write(header[:])
+		_, err := io.CopyN(chunk, r, int64(binary.BigEndian.Uint32(header[:])))
+		return err
+	}
+
+	n, err := io.CopyN(chunk, r, spillChunk)
+	if err != nil || n == 0 {
+		return err
+	}
+
+	//end the chunk at an entry boundary
+	rest, err := r.ReadBytes(t._sepByte())
+	chunk.Write(rest)
+
+	return err
+}
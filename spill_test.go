@@ -0,0 +1,86 @@
+package easylog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCatchUpRotates(t *testing.T) {
+	tmp := t.TempDir()
+	dir, fallback := filepath.Join(tmp, "primary"), filepath.Join(tmp, "fallback")
+	const maxSize = 1 << 20
+	l := newTestLog(t, Options{Dir: dir, FallbackDir: fallback, MaxFileSize: maxSize}, realClock{})
+
+	//the primary directory can't take files while a file is in its place
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := strings.Repeat("x", 1000)
+	const spilled = 3000
+	for i := 0; i < spilled; i++ {
+		l.Write([]byte(fmt.Sprintf("%05d %s", i, entry)))
+		if i%100 == 99 {
+			if err := l.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if _, err := os.Stat(filepath.Join(fallback, "test.log")); err != nil {
+		t.Fatalf("nothing spilled: %v", err)
+	}
+
+	if err := os.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	l.Write([]byte(fmt.Sprintf("%05d after", spilled)))
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(fallback, "test.log")); !os.IsNotExist(err) {
+		t.Fatalf("spilled file is kept: %v", err)
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) < 3 {
+		t.Fatalf("caught up into %d files, want rotations", len(files))
+	}
+	for _, f := range files {
+		info, err := f.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() > maxSize {
+			t.Errorf("%s has %d bytes, more than the max size", f.Name(), info.Size())
+		}
+	}
+
+	r := Open(dir, "test.log")
+	defer r.Close()
+	next := 0
+	for r.Next() {
+		if want := fmt.Sprintf("%05d ", next); !strings.HasPrefix(string(r.Record().Line)+" ", want) {
+			t.Fatalf("entry %d is %.10q", next, r.Record().Line)
+		}
+		next++
+	}
+	if r.Err() != nil {
+		t.Fatal(r.Err())
+	}
+	if next != spilled+1 {
+		t.Fatalf("read %d entries, want %d", next, spilled+1)
+	}
+}
@@ -0,0 +1,358 @@
+package easylog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Spool is a write-ahead spool in front of a remote writer such as a
+//NetworkSink or a kafkasink.Sink. add it to a logger with AddOutput. Write
+//appends data to a file in the spool directory and returns, and a goroutine
+//delivers it to the remote writer in order, retrying with backoff until the
+//writer accepts it. the delivered offset is stored next to the data, so data
+//accepted before a restart is delivered after it. delivered data is dropped
+//from the file when the spool empties, or under steady load, once it
+//outweighs the pending data.
+//
+//delivery is at least once: a batch sent right before a crash may be sent
+//again. the remote writer must return an error for data it didn't deliver,
+//so don't give a NetworkSink a fallback writer when spooling it.
+type Spool struct {
+	w    io.Writer
+	path string
+
+	mu        sync.Mutex
+	wal       *os.File
+	size      int64
+	offset    int64
+	maxSize   int64
+	separator string
+	closed    bool
+
+	clock clock
+	wake  chan struct{}
+	quit  chan struct{}
+	done  chan struct{}
+}
+
+const (
+	defaultSpoolSize = 256 * 1024 * 1024
+	spoolChunk       = 256 * 1024
+	//delivered bytes at the start of the spool file that make it compacted
+	spoolCompact = 64 * spoolChunk
+)
+
+//open the spool in dir, creating it if needed, and start delivering to w.
+//data left by an earlier run is delivered first.
+func NewSpool(dir string, w io.Writer) (*Spool, error) {
+	if w == nil {
+		return nil, errors.New("easylog: spool needs a writer")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, "spool.wal")
+	wal, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := wal.Stat()
+	if err != nil {
+		wal.Close()
+		return nil, err
+	}
+
+	s := &Spool{
+		w:         w,
+		path:      path,
+		wal:       wal,
+		size:      info.Size(),
+		maxSize:   defaultSpoolSize,
+		separator: "\n",
+		clock:     realClock{},
+		wake:      make(chan struct{}, 1),
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	s.offset = _readSpoolOffset(path + ".offset")
+	if s.offset > s.size {
+		//the spool was cut short, deliver what is left
+		s.offset = 0
+	}
+
+	go s._run()
+
+	return s, nil
+}
+
+//set how many undelivered bytes the spool holds on disk. when it is full,
+//Write returns an error and the data is lost. the default is 256MB.
+func (s *Spool) SetMaxSize(MaxSize int64) error {
+	if MaxSize < spoolChunk {
+		MaxSize = spoolChunk
+	}
+
+	s.mu.Lock()
+	s.maxSize = MaxSize
+	s.mu.Unlock()
+
+	return nil
+}
+
+//set the terminator of the records in the spooled data. chunks are only cut
+//after one, so records reach the remote writer whole. AddOutput sets it to
+//the logger's record separator. if sep == "", the default "\n" is used.
+func (s *Spool) SetRecordSeparator(sep string) error {
+	if sep == "" {
+		sep = "\n"
+	}
+
+	s.mu.Lock()
+	s.separator = sep
+	s.mu.Unlock()
+
+	return nil
+}
+
+//number of bytes accepted but not yet delivered
+func (s *Spool) Pending() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.size - s.offset
+}
+
+//append p to the spool. it returns once p is on disk, not when it is
+//delivered.
+func (s *Spool) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return 0, ErrClosed
+	}
+
+	if s.size-s.offset+int64(len(p)) > s.maxSize {
+		return 0, fmt.Errorf("easylog: spool %s is full", s.path)
+	}
+
+	n, err := s.wal.Write(p)
+	s.size += int64(n)
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return n, err
+}
+
+//try once more to deliver pending data, then stop. what is still pending
+//stays on disk for the next run.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrClosed
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.quit)
+	<-s.done
+
+	return s.wal.Close()
+}
+
+func (s *Spool) _run() {
+	defer close(s.done)
+
+	buf := make([]byte, spoolChunk)
+	var backoff time.Duration
+
+	for {
+		more, err := s._deliver(buf)
+
+		var wait <-chan time.Time
+		var tm timer
+		switch {
+		case err != nil:
+			if backoff == 0 {
+				backoff = minNetBackoff
+			} else if backoff *= 2; backoff > maxNetBackoff {
+				backoff = maxNetBackoff
+			}
+			tm = s.clock.NewTimer(backoff)
+			wait = tm.C()
+		case more:
+			backoff = 0
+			continue
+		default:
+			backoff = 0
+		}
+
+		select {
+		case <-s.wake:
+		case <-wait:
+		case <-s.quit:
+			if tm != nil {
+				tm.Stop()
+			}
+			for {
+				if more, err := s._deliver(buf); err != nil || !more {
+					return
+				}
+			}
+		}
+	}
+}
+
+//send the next chunk of pending data. it reports whether more is pending.
+func (s *Spool) _deliver(buf []byte) (bool, error) {
+	s.mu.Lock()
+	offset, size, sep := s.offset, s.size, s.separator
+	if offset == size {
+		if size > 0 {
+			s._reset()
+		}
+		s.mu.Unlock()
+		return false, nil
+	}
+	//under steady load the spool never empties, drop the delivered part
+	//once it outweighs the pending one, so copying stays cheap
+	if offset >= spoolCompact && offset >= size-offset {
+		if err := s._compact(); err != nil {
+			s.mu.Unlock()
+			return false, err
+		}
+		offset, size = s.offset, s.size
+	}
+	s.mu.Unlock()
+
+	chunk := buf
+	if size-offset < int64(len(chunk)) {
+		chunk = chunk[:size-offset]
+	}
+
+	n, err := s.wal.ReadAt(chunk, offset)
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	chunk = chunk[:n]
+
+	//keep records whole, unless a single record is larger than a chunk
+	if offset+int64(n) < size {
+		if i := bytes.LastIndex(chunk, []byte(sep)); i >= 0 {
+			chunk = chunk[:i+len(sep)]
+		}
+	}
+
+	if _, err := s.w.Write(chunk); err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	s.offset += int64(len(chunk))
+	offset = s.offset
+	s.mu.Unlock()
+
+	if err := _writeSpoolOffset(s.path+".offset", offset); err != nil {
+		return false, err
+	}
+
+	return offset < size, nil
+}
+
+//empty the spool once everything is delivered. the offset is stored first,
+//so a crash in between only causes a redelivery. must be called with s.mu
+//held.
+func (s *Spool) _reset() {
+	if err := _writeSpoolOffset(s.path+".offset", 0); err != nil {
+		return
+	}
+
+	if err := s.wal.Truncate(0); err != nil {
+		return
+	}
+
+	s.size, s.offset = 0, 0
+}
+
+//replace the spool file by its pending data. like _reset, the offset is
+//stored first, so a crash in between only causes a redelivery. must be
+//called with s.mu held.
+func (s *Spool) _compact() error {
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, io.NewSectionReader(s.wal, s.offset, s.size-s.offset))
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = _writeSpoolOffset(s.path+".offset", 0)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	//files open elsewhere can't be replaced on every platform
+	s.wal.Close()
+	if err = os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		_writeSpoolOffset(s.path+".offset", s.offset)
+	} else {
+		s.size, s.offset = s.size-s.offset, 0
+	}
+
+	wal, oerr := os.OpenFile(s.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if oerr != nil {
+		return oerr
+	}
+	s.wal = wal
+
+	return err
+}
+
+func _readSpoolOffset(path string) int64 {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+
+	offset, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil || offset < 0 {
+		return 0
+	}
+
+	return offset
+}
+
+//replace the offset file atomically, so it is never half written
+func _writeSpoolOffset(path string, offset int64) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatInt(offset, 10)+"\n"), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,167 @@
+package easylog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+//loadWriter takes spool deliveries and, like steady load, writes as much
+//new data to the spool for each delivery, until total bytes were written
+type loadWriter struct {
+	mu       sync.Mutex
+	spool    *Spool
+	got      bytes.Buffer
+	next     int
+	written  int
+	total    int
+	maxSize  int64
+	walPath  string
+	writeErr error
+}
+
+func (w *loadWriter) _line() []byte {
+	line := fmt.Sprintf("%06d %s\n", w.next, strings.Repeat("x", 1000))
+	w.next++
+	w.written += len(line)
+
+	return []byte(line)
+}
+
+func (w *loadWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.got.Write(p)
+	if info, err := os.Stat(w.walPath); err == nil && info.Size() > w.maxSize {
+		w.maxSize = info.Size()
+	}
+
+	for n := 0; n < len(p) && w.written < w.total; {
+		line := w._line()
+		if _, err := w.spool.Write(line); err != nil && w.writeErr == nil {
+			w.writeErr = err
+		}
+		n += len(line)
+	}
+
+	return len(p), nil
+}
+
+func TestSpoolCompactsUnderLoad(t *testing.T) {
+	dir := t.TempDir()
+	w := &loadWriter{total: 3 * spoolCompact, walPath: filepath.Join(dir, "spool.wal")}
+
+	//hold deliveries until the backlog is written
+	w.mu.Lock()
+	s, err := NewSpool(dir, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	w.spool = s
+	for w.written < 4*spoolChunk {
+		if _, err := s.Write(w._line()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.mu.Unlock()
+
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		w.mu.Lock()
+		done := w.written >= w.total && w.got.Len() == w.written
+		w.mu.Unlock()
+		if done {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the spool is never delivered")
+		}
+		runtime.Gosched()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.writeErr != nil {
+		t.Fatal(w.writeErr)
+	}
+	if w.maxSize >= 2*spoolCompact {
+		t.Errorf("spool file grew to %d bytes under load", w.maxSize)
+	}
+	for i, line := range strings.SplitAfter(w.got.String(), "\n")[:w.next] {
+		if !strings.HasPrefix(line, fmt.Sprintf("%06d ", i)) {
+			t.Fatalf("delivery %d is %.10q", i, line)
+		}
+	}
+}
+
+//chunkWriter keeps every delivery apart
+type chunkWriter struct {
+	mu     sync.Mutex
+	chunks [][]byte
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.chunks = append(w.chunks, append([]byte(nil), p...))
+
+	return len(p), nil
+}
+
+func TestSpoolRecordSeparator(t *testing.T) {
+	l := newTestLog(t, Options{RecordSeparator: "\x00"}, realClock{})
+	w := &chunkWriter{}
+
+	//hold deliveries until every record is spooled
+	w.mu.Lock()
+	s, err := NewSpool(t.TempDir(), w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	l.AddOutput(s)
+
+	var want bytes.Buffer
+	for i := 0; i < 10; i++ {
+		//records with newlines inside, larger than half a chunk
+		record := fmt.Sprintf("%02d %s\x00", i, strings.Repeat("line\n", spoolChunk/8))
+		want.WriteString(record)
+		if _, err := s.Write([]byte(record)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.mu.Unlock()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for s.Pending() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the spool is never delivered")
+		}
+		runtime.Gosched()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var got bytes.Buffer
+	for i, chunk := range w.chunks {
+		if !bytes.HasSuffix(chunk, []byte("\x00")) {
+			t.Errorf("delivery %d of %d bytes ends inside a record", i, len(chunk))
+		}
+		got.Write(chunk)
+	}
+	if len(w.chunks) < 2 {
+		t.Errorf("%d deliveries, want the records spread over chunks", len(w.chunks))
+	}
+	if !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Error("delivered data differs from the spooled data")
+	}
+}
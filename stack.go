@@ -0,0 +1,107 @@
+package easylog
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+//a stack trace level above every entry, turning stack traces off
+const StacktraceOff = Level(numLevels)
+
+const defaultStacktraceDepth = 32
+
+//add the stack of the logging goroutine as the "stack" field of entries at
+//level or above, e.g. SetStacktraceLevel(ErrorLevel). frames are written one
+//per line as "function\n\tfile:line", starting at the call site. entries
+//logged through Slog, Logr and NewStdLogger get stack traces too.
+//the default is StacktraceOff.
+func (t *EasyLog) SetStacktraceLevel(level Level) error {
+	if !level.valid() && level != StacktraceOff {
+		return fmt.Errorf("easylog: invalid level %d", int32(level))
+	}
+
+	atomic.StoreInt32(&t.stackLevel, int32(level))
+
+	return nil
+}
+
+func (t *EasyLog) GetStacktraceLevel() Level {
+	return Level(atomic.LoadInt32(&t.stackLevel))
+}
+
+//set how many frames a stack trace holds at most. if depth <= 0, the
+//default of 32 is used.
+func (t *EasyLog) SetStacktraceDepth(depth int) error {
+	if depth <= 0 {
+		depth = defaultStacktraceDepth
+	}
+
+	atomic.StoreInt32(&t.stackDepth, int32(depth))
+
+	return nil
+}
+
+func (t *EasyLog) GetStacktraceDepth() int {
+	return int(atomic.LoadInt32(&t.stackDepth))
+}
+
+func (t *EasyLog) _wantStack(level Level) bool {
+	return level >= t.GetStacktraceLevel()
+}
+
+//the stack of the calling goroutine, starting at the frame runtime.Caller(skip)
+//would report to the caller of _stacktrace
+func _stacktrace(skip, depth int) string {
+	pcs := make([]uintptr, depth)
+	//runtime.Callers and _stacktrace are frames too
+	n := runtime.Callers(skip+2, pcs)
+
+	return _formatStack(pcs[:n])
+}
+
+//the stack of the calling goroutine, starting at the frame of pc, a program
+//counter taken by runtime.Callers lower on the same stack, as slog records do.
+//if pc isn't on the stack, it starts at the caller of _stacktraceFrom.
+func _stacktraceFrom(pc uintptr, depth int) string {
+	//room for the frames between pc and here
+	pcs := make([]uintptr, depth+16)
+	n := runtime.Callers(2, pcs)
+	pcs = pcs[:n]
+
+	for i, p := range pcs {
+		if p == pc {
+			pcs = pcs[i:]
+			break
+		}
+	}
+	if len(pcs) > depth {
+		pcs = pcs[:depth]
+	}
+
+	return _formatStack(pcs)
+}
+
+func _formatStack(pcs []uintptr) string {
+	frames := runtime.CallersFrames(pcs)
+
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+	}
+
+	return b.String()
+}
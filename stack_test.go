@@ -0,0 +1,52 @@
+package easylog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+//the stack field of each entry in the log file
+func readStacks(tb testing.TB, l *EasyLog) []string {
+	tb.Helper()
+
+	if err := l.Flush(); err != nil {
+		tb.Fatal(err)
+	}
+
+	var stacks []string
+	for _, line := range strings.Split(strings.TrimSpace(readLog(tb, l)), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			tb.Fatalf("%q: %v", line, err)
+		}
+		stack, _ := entry["stack"].(string)
+		stacks = append(stacks, stack)
+	}
+
+	return stacks
+}
+
+func TestStacktraceAdapters(t *testing.T) {
+	l := newTestLog(t, Options{Format: JSONFormat}, realClock{})
+	l.SetStacktraceLevel(WarnLevel)
+
+	l.Warnf("native")
+	l.NewStdLogger(WarnLevel).Print("std")
+	l.Logr().Error(nil, "logr")
+	l.NewStdLogger(InfoLevel).Print("below the level")
+
+	const top = "github.com/carr123/easylog.TestStacktraceAdapters\n\t"
+	stacks := readStacks(t, l)
+	if len(stacks) != 4 {
+		t.Fatalf("got %d entries, want 4", len(stacks))
+	}
+	for i, name := range []string{"native", "std", "logr"} {
+		if !strings.HasPrefix(stacks[i], top) {
+			t.Errorf("%s stack starts at %q, want the call site", name, stacks[i])
+		}
+	}
+	if stacks[3] != "" {
+		t.Errorf("entry below the stack trace level has stack %q", stacks[3])
+	}
+}
@@ -0,0 +1,70 @@
+package easylog
+
+import (
+	"expvar"
+	"strings"
+	"sync/atomic"
+)
+
+type counters struct {
+	entries     [numLevels]uint64
+	writes      uint64
+	bytes       uint64
+	rotations   uint64
+	errors      uint64
+	flushErrors uint64
+	alertDrops  uint64
+}
+
+//Stats is a snapshot of a logger's counters
+type Stats struct {
+	//leveled entries accepted, keyed by lower case level name
+	Entries map[string]uint64
+	//calls to Write
+	Writes uint64
+	//bytes written to the log file
+	BytesFlushed uint64
+	//entries currently waiting in the write queue, and its capacity
+	QueueLen int
+	QueueCap int
+	//entries discarded by the queue policy or the in-flight limit
+	Dropped uint64
+	//files rotated
+	Rotations uint64
+	//errors reported through OnError
+	Errors uint64
+	//flushes that failed to write their batch, see LastError
+	FlushErrors uint64
+	//entries not handed to OnLevel callbacks because they fell behind
+	AlertsDropped uint64
+}
+
+//return a snapshot of the logger's counters
+func (t *EasyLog) Stats() Stats {
+	st := Stats{
+		Entries:       make(map[string]uint64, numLevels),
+		Writes:        atomic.LoadUint64(&t.counters.writes),
+		BytesFlushed:  atomic.LoadUint64(&t.counters.bytes),
+		QueueLen:      len(t.pipe),
+		QueueCap:      cap(t.pipe),
+		Dropped:       t.Dropped(),
+		Rotations:     atomic.LoadUint64(&t.counters.rotations),
+		Errors:        atomic.LoadUint64(&t.counters.errors),
+		FlushErrors:   atomic.LoadUint64(&t.counters.flushErrors),
+		AlertsDropped: atomic.LoadUint64(&t.counters.alertDrops),
+	}
+
+	for i := 0; i < numLevels; i++ {
+		st.Entries[strings.ToLower(Level(i).String())] = atomic.LoadUint64(&t.counters.entries[i])
+	}
+
+	return st
+}
+
+//return an expvar.Var reporting Stats, for publishing with expvar.Publish or
+//scraping by a Prometheus expvar exporter
+func (t *EasyLog) Expvar() expvar.Var {
+	return expvar.Func(func() interface{} {
+		return t.Stats()
+	})
+}
@@ -0,0 +1,33 @@
+package easylog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStatsWrites(t *testing.T) {
+	l := newTestLog(t, Options{}, realClock{})
+
+	l.Write([]byte("write"))
+	l.WriteString("write string")
+	l.WriteBatch([][]byte{[]byte("batch 1"), []byte("batch 2")})
+	l.WriteFrom(strings.NewReader("from"))
+	if _, err := l.WriteSync([]byte("sync")); err != nil {
+		t.Fatal(err)
+	}
+	l.Infof("leveled")
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	st := l.Stats()
+	if st.Writes != 6 {
+		t.Errorf("Writes is %d, want 6", st.Writes)
+	}
+	if st.Entries["info"] != 1 {
+		t.Errorf("info entries is %d, want 1", st.Entries["info"])
+	}
+	if want := uint64(len(readLog(t, l))); st.BytesFlushed != want {
+		t.Errorf("BytesFlushed is %d, want the %d bytes of the file", st.BytesFlushed, want)
+	}
+}
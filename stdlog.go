@@ -0,0 +1,65 @@
+package easylog
+
+import (
+	"log"
+	"runtime"
+	"strings"
+)
+
+//stdWriter turns each line written by a *log.Logger into a leveled entry
+type stdWriter struct {
+	log   *EasyLog
+	level Level
+}
+
+func (w *stdWriter) Write(p []byte) (int, error) {
+	if !w.log._wanted(w.level, nil) {
+		return len(p), nil
+	}
+
+	var fields Fields
+	if w.log.GetReportCaller() {
+		//Write, log.(*Logger).Output, then log.Printf or a sibling
+		if pc, file, line, ok := runtime.Caller(3); ok {
+			frame := runtime.Frame{PC: pc, File: file, Line: line}
+			if fn := runtime.FuncForPC(pc); fn != nil {
+				frame.Function = fn.Name()
+			}
+			fields = Fields{}
+			_addCaller(fields, frame)
+		}
+	}
+	if w.log._wantStack(w.level) {
+		if fields == nil {
+			fields = Fields{}
+		}
+		fields["stack"] = _stacktrace(3, w.log.GetStacktraceDepth())
+	}
+
+	w.log._logMsg(w.log._now(), w.level, strings.TrimSuffix(string(p), "\n"), fields)
+
+	return len(p), nil
+}
+
+//return a *log.Logger whose output is logged through t at level, for code
+//that expects the standard library logger. timestamps come from t, so the
+//logger has no flags or prefix.
+func (t *EasyLog) NewStdLogger(level Level) *log.Logger {
+	return log.New(&stdWriter{log: t, level: level}, "", 0)
+}
+
+//send the output of the standard log package to t at InfoLevel. the returned
+//function restores the previous output, flags and prefix.
+func (t *EasyLog) RedirectStdLog() (restore func()) {
+	w, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+
+	log.SetOutput(&stdWriter{log: t, level: InfoLevel})
+	log.SetFlags(0)
+	log.SetPrefix("")
+
+	return func() {
+		log.SetOutput(w)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	}
+}
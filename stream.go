@@ -0,0 +1,120 @@
+package easylog
+
+import (
+	"bytes"
+	"io"
+	"sync/atomic"
+)
+
+const streamChunk = 64 * 1024
+
+//copy r to the log as one entry, 64KB at a time, without holding all of it
+//in memory. use it for large payloads such as request dumps. entries queued
+//earlier are written first, later ones wait until r is drained. a missing
+//record separator is added at the end.
+//the entry isn't split across files: the file is rotated first if the entry
+//doesn't fit, as far as its size is known from a Len method or the first
+//chunk, and otherwise may grow beyond the max file size.
+//audit mode, redaction and SetEncoding need the whole entry, so then r is
+//read into memory and passed to Write.
+func (t *EasyLog) WriteFrom(r io.Reader) (n int64, err error) {
+	if s := t._shard(nil); s != t {
+		return s.WriteFrom(r)
+	}
+
+	if t.GetAuditMode() || len(t._redactors()) > 0 || t._consoleMode() == consoleExclusive || t.GetEncoding() != nil {
+		p, err := io.ReadAll(r)
+		if err != nil {
+			return 0, err
+		}
+		m, err := t.Write(p)
+		return int64(m), err
+	}
+
+	atomic.AddUint64(&t.counters.writes, 1)
+
+	err = t._do(func(data *bytes.Buffer) error {
+		t._drain(data)
+		if err := t._flush(data); err != nil {
+			return err
+		}
+
+		n, err = t._stream(r)
+		return err
+	})
+
+	return n, err
+}
+
+//write r to the sink chunk by chunk. only called from the serve goroutine.
+func (t *EasyLog) _stream(r io.Reader) (n int64, err error) {
+	t.streaming, t.streamStarted = true, false
+	defer func() { t.streaming = false }()
+
+	sep := []byte(t.GetRecordSeparator())
+	p := make([]byte, streamChunk)
+	var tail []byte
+	var chunk bytes.Buffer
+
+	for {
+		m, rerr := io.ReadFull(r, p)
+		if m > 0 {
+			n += int64(m)
+			if !t.streamStarted {
+				t.streamSize = int64(m)
+				if l, ok := r.(interface{ Len() int }); ok {
+					t.streamSize += int64(l.Len())
+				}
+			}
+
+			tail = append(tail, p[:m]...)
+			if len(tail) > len(sep) {
+				tail = tail[len(tail)-len(sep):]
+			}
+
+			chunk.Reset()
+			chunk.Write(p[:m])
+			if err := t._streamChunk(&chunk); err != nil {
+				return n, err
+			}
+		}
+
+		if rerr == io.EOF || rerr == io.ErrUnexpectedEOF {
+			break
+		}
+		if rerr != nil {
+			return n, rerr
+		}
+	}
+
+	if n > 0 && !bytes.Equal(tail, sep) {
+		chunk.Reset()
+		chunk.Write(sep)
+		if err := t._streamChunk(&chunk); err != nil {
+			return n, err
+		}
+	}
+
+	if n > 0 {
+		t.dirty = true
+		t._syncIfDue()
+	}
+
+	return n, nil
+}
+
+func (t *EasyLog) _streamChunk(chunk *bytes.Buffer) error {
+	size := chunk.Len()
+	t._writeOutputs(chunk)
+	if err := t._encrypt(chunk); err != nil {
+		return err
+	}
+
+	if _, err := t._sinkWrite(chunk.Bytes()); err != nil {
+		t._reportError(err)
+		return err
+	}
+	atomic.AddUint64(&t.counters.bytes, uint64(size))
+
+	return nil
+}
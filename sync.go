@@ -0,0 +1,146 @@
+package easylog
+
+import (
+	"bytes"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+//SyncPolicy controls when the log file is fsynced after a flush.
+//without fsync, flushed data may sit in the OS page cache and be lost if the
+//machine crashes.
+type SyncPolicy struct {
+	mode     int
+	interval time.Duration
+}
+
+const (
+	syncNever = iota
+	syncEveryFlush
+	syncInterval
+)
+
+var (
+	//leave syncing to the OS. this is the default.
+	SyncNever = SyncPolicy{mode: syncNever}
+	//fsync after every flush
+	SyncEveryFlush = SyncPolicy{mode: syncEveryFlush}
+)
+
+//fsync at most once every d, as long as there is unsynced data
+func SyncInterval(d time.Duration) SyncPolicy {
+	if d <= 0 {
+		return SyncEveryFlush
+	}
+	return SyncPolicy{mode: syncInterval, interval: d}
+}
+
+func (p SyncPolicy) String() string {
+	switch p.mode {
+	case syncNever:
+		return "Never"
+	case syncEveryFlush:
+		return "EveryFlush"
+	}
+	return fmt.Sprintf("Interval(%v)", p.interval)
+}
+
+//set when flushed data is fsynced to disk, trading durability for throughput
+func (t *EasyLog) SetSyncPolicy(policy SyncPolicy) error {
+	t.syncPolicy.Store(policy)
+	return nil
+}
+
+func (t *EasyLog) GetSyncPolicy() SyncPolicy {
+	p, _ := t.syncPolicy.Load().(SyncPolicy)
+	return p
+}
+
+//fsync the active file if the sync policy asks for it.
+//only called from the serve goroutine.
+func (t *EasyLog) _syncIfDue() {
+	if !t.dirty {
+		return
+	}
+
+	switch p := t.GetSyncPolicy(); p.mode {
+	case syncEveryFlush:
+		t._fsync()
+	case syncInterval:
+		if t._now().Sub(t.lastSync) >= p.interval {
+			t._fsync()
+		}
+	}
+}
+
+//fsync the active log file, or sync the sink if it supports it. only called
+//from the serve goroutine.
+func (t *EasyLog) _fsync() error {
+	var err error
+	if s, ok := t._sink().(interface{ Sync() error }); ok {
+		err = s.Sync()
+	}
+	t._reportError(err)
+	if err == nil {
+		t.dirty = false
+		t.lastSync = t._now()
+	}
+
+	return err
+}
+
+type syncRequest struct {
+	buf  *bytes.Buffer
+	done chan error
+}
+
+//write p to disk and fsync the log file before returning. entries queued
+//earlier by Write are written first, so ordering is preserved. use it for
+//audit records and panic handlers that must not be lost on a crash.
+func (t *EasyLog) WriteSync(p []byte) (n int, err error) {
+	atomic.AddUint64(&t.counters.writes, 1)
+
+	buf := t._getBuffer()
+	t._writeEntry(buf, p)
+	t._redact(buf)
+	n = len(p)
+
+	if err := t._shard(nil)._writeSync(buf); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+//make every Write and leveled entry synchronous, as if written by WriteSync.
+//this trades throughput for durability.
+func (t *EasyLog) SetSynchronous(enable bool) error {
+	var v int32
+	if enable {
+		v = 1
+	}
+
+	atomic.StoreInt32(&t.synchronous, v)
+
+	return nil
+}
+
+func (t *EasyLog) GetSynchronous() bool {
+	return atomic.LoadInt32(&t.synchronous) == 1
+}
+
+func (t *EasyLog) _writeSync(buf *bytes.Buffer) error {
+	t.closeMu.RLock()
+	if t.closed {
+		t.closeMu.RUnlock()
+		t._putBuffer(buf)
+		return ErrClosed
+	}
+
+	req := syncRequest{buf: buf, done: make(chan error, 1)}
+	t.syncReq <- req
+	t.closeMu.RUnlock()
+
+	return <-req.done
+}
@@ -0,0 +1,166 @@
+package easylog
+
+import (
+	"container/list"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+const defaultMaxOpenKeys = 64
+
+//return a Logger writing to a directory of its own below the log directory,
+//e.g. ForKey("tenant-42") writes to <dir>/tenant-42/app.log. multi-tenant
+//services use it to keep each customer's entries apart. the directory is
+//created on first use, and the key's logger takes t's rotation, retention,
+//format, level, global fields, redactors and flush settings. only the
+//loggers of the keys written to most recently are kept open, see
+//SetMaxOpenKeys; the returned Logger opens its key's logger again when
+//needed.
+//if the key's file can't be opened, the error is reported to the OnError
+//func and entries go to the log file instead.
+func (t *EasyLog) ForKey(key string) *Logger {
+	k, err := t._acquireKey(key)
+	if err != nil {
+		t._reportError(err)
+		return &Logger{log: t}
+	}
+	t._releaseKey(k)
+
+	return &Logger{log: t, key: key}
+}
+
+//set how many per-key loggers stay open. when a key beyond the limit is
+//written to, the logger of the least recently used key writes its pending
+//entries and is closed with its file and goroutines, and opened again by
+//its next entry. the default is 64.
+func (t *EasyLog) SetMaxOpenKeys(MaxOpenKeys int) error {
+	if MaxOpenKeys < 1 {
+		MaxOpenKeys = 1
+	}
+
+	atomic.StoreInt32(&t.maxOpenKeys, int32(MaxOpenKeys))
+
+	return nil
+}
+
+func (t *EasyLog) GetMaxOpenKeys() int {
+	return int(atomic.LoadInt32(&t.maxOpenKeys))
+}
+
+//the logger of a key, with the entries being logged through it
+type keyLog struct {
+	key     string
+	log     *EasyLog
+	elem    *list.Element
+	users   int
+	evicted bool
+	//closed once an evicted logger is closed
+	done chan struct{}
+}
+
+//return the logger of key, opening it if needed, and mark it used until
+//_releaseKey. it counts as the most recently used key.
+func (t *EasyLog) _acquireKey(key string) (*keyLog, error) {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+		return nil, fmt.Errorf("easylog: invalid key %q", key)
+	}
+
+	for {
+		t.keyMu.Lock()
+
+		t.closeMu.RLock()
+		closed := t.closed
+		t.closeMu.RUnlock()
+		if closed {
+			t.keyMu.Unlock()
+			return nil, ErrClosed
+		}
+
+		if t.keyLogs == nil {
+			t.keyLogs = map[string]*keyLog{}
+			t.keyOpen = list.New()
+		}
+
+		k := t.keyLogs[key]
+		if k != nil && k.evicted {
+			//two loggers must not write the same file, wait for the old one
+			done := k.done
+			t.keyMu.Unlock()
+			<-done
+			continue
+		}
+
+		if k == nil {
+			cfg := t._fileConfig()
+			child, err := t._newChild(filepath.Join(cfg.root, key), cfg.name)
+			if err != nil {
+				t.keyMu.Unlock()
+				return nil, err
+			}
+			k = &keyLog{key: key, log: child, done: make(chan struct{})}
+			k.elem = t.keyOpen.PushFront(k)
+			t.keyLogs[key] = k
+		} else {
+			t.keyOpen.MoveToFront(k.elem)
+		}
+		k.users++
+
+		var evict []*keyLog
+		for t.keyOpen.Len() > t.GetMaxOpenKeys() {
+			old := t.keyOpen.Remove(t.keyOpen.Back()).(*keyLog)
+			old.evicted = true
+			if old.users == 0 {
+				evict = append(evict, old)
+			}
+		}
+		t.keyMu.Unlock()
+
+		for _, old := range evict {
+			t._closeKey(old)
+		}
+
+		return k, nil
+	}
+}
+
+//end a use of k. an evicted logger is closed by its last user.
+func (t *EasyLog) _releaseKey(k *keyLog) {
+	t.keyMu.Lock()
+	k.users--
+	last := k.evicted && k.users == 0
+	t.keyMu.Unlock()
+
+	if last {
+		t._closeKey(k)
+	}
+}
+
+//close an evicted logger, pending entries are written first
+func (t *EasyLog) _closeKey(k *keyLog) {
+	if err := k.log.Close(); err != ErrClosed {
+		t._reportError(err)
+	}
+
+	t.keyMu.Lock()
+	if t.keyLogs[k.key] == k {
+		delete(t.keyLogs, k.key)
+	}
+	t.keyMu.Unlock()
+
+	close(k.done)
+}
+
+//the per-key loggers open now
+func (t *EasyLog) _keyLoggers() []*EasyLog {
+	t.keyMu.Lock()
+	defer t.keyMu.Unlock()
+
+	logs := make([]*EasyLog, 0, len(t.keyLogs))
+	for _, k := range t.keyLogs {
+		logs = append(logs, k.log)
+	}
+
+	return logs
+}
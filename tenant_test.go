@@ -0,0 +1,121 @@
+package easylog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func openKeys(l *EasyLog) int {
+	l.keyMu.Lock()
+	defer l.keyMu.Unlock()
+
+	return len(l.keyLogs)
+}
+
+func TestMaxOpenKeys(t *testing.T) {
+	l := newTestLog(t, Options{}, realClock{})
+	l.SetMaxOpenKeys(2)
+	dir, name := l.GetDir()
+
+	a, b, c := l.ForKey("a"), l.ForKey("b"), l.ForKey("c")
+	if n := openKeys(l); n != 2 {
+		t.Fatalf("%d key loggers open, want 2", n)
+	}
+
+	//a was evicted by c, writing to it opens it again and evicts b
+	a.Infof("to a")
+	c.Infof("to c")
+	if n := openKeys(l); n != 2 {
+		t.Fatalf("%d key loggers open after writes, want 2", n)
+	}
+	b.Infof("to b")
+	a.Infof("to a again")
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[string][]string{
+		"a": {"to a", "to a again"},
+		"b": {"to b"},
+		"c": {"to c"},
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, key, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSuffix(string(got), "\n"), "\n")
+		if len(lines) != len(want) {
+			t.Fatalf("key %s holds %q, want %q", key, got, want)
+		}
+		for i := range want {
+			if !strings.HasSuffix(lines[i], want[i]) {
+				t.Fatalf("key %s holds %q, want %q", key, got, want)
+			}
+		}
+	}
+}
+
+func TestMaxOpenKeysConcurrent(t *testing.T) {
+	l := newTestLog(t, Options{}, realClock{})
+	l.SetMaxOpenKeys(3)
+	dir, name := l.GetDir()
+
+	const keys, writers, entries = 10, 8, 200
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < entries; i++ {
+				l.ForKey(fmt.Sprintf("k%d", (w+i)%keys)).Infof("writer %d entry %d", w, i)
+			}
+		}(w)
+	}
+	wg.Wait()
+	if n := openKeys(l); n > 3 {
+		t.Fatalf("%d key loggers open, want at most 3", n)
+	}
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	//no entry is lost to an eviction
+	var total int
+	for k := 0; k < keys; k++ {
+		got, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("k%d", k), name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += strings.Count(string(got), "\n")
+	}
+	if total != writers*entries {
+		t.Fatalf("%d entries written, want %d", total, writers*entries)
+	}
+}
+
+func TestForKeyGlobalFields(t *testing.T) {
+	l := newTestLog(t, Options{Format: JSONFormat}, realClock{})
+	l.SetHostFields(true)
+	l.SetGlobalFields(map[string]string{"app": "shop"})
+	dir, name := l.GetDir()
+
+	l.ForKey("a").Infof("to a")
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "a", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, _ := os.Hostname()
+	for _, want := range []string{`"host":"` + host + `"`, fmt.Sprintf(`"pid":%d`, os.Getpid()), `"app":"shop"`} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("key entry %q lacks %s", got, want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package easylog
+
+import "time"
+
+//start timing the operation name. the returned func logs how long it took
+//at InfoLevel, typically deferred:
+//
+//  defer log.TimeOp("load config")()
+func (t *EasyLog) TimeOp(name string) func() {
+	return t._timeOp(nil, name, 0)
+}
+
+//like TimeOp, but only operations taking longer than threshold are logged,
+//at WarnLevel. use it to catch slow queries and requests.
+func (t *EasyLog) TimeOpOver(name string, threshold time.Duration) func() {
+	return t._timeOp(nil, name, threshold)
+}
+
+func (l *Logger) TimeOp(name string) func() {
+	return l._timeOp(name, 0)
+}
+
+func (l *Logger) TimeOpOver(name string, threshold time.Duration) func() {
+	return l._timeOp(name, threshold)
+}
+
+//a ForKey Logger looks its key up once the operation ends
+func (l *Logger) _timeOp(name string, threshold time.Duration) func() {
+	if l.key == "" {
+		return l.log._timeOp(l.fields, name, threshold)
+	}
+
+	start := l.log._now()
+
+	return func() {
+		log, k := l._acquire()
+		log._endOp(l.fields, name, start, threshold)
+		l._release(k)
+	}
+}
+
+func (t *EasyLog) _timeOp(fields Fields, name string, threshold time.Duration) func() {
+	start := t._now()
+
+	return func() {
+		t._endOp(fields, name, start, threshold)
+	}
+}
+
+//log an operation started at start. the entry carries the "op" and
+//"elapsed_ms" fields.
+func (t *EasyLog) _endOp(fields Fields, name string, start time.Time, threshold time.Duration) {
+	now := t._now()
+	elapsed := now.Sub(start)
+
+	level := InfoLevel
+	if threshold > 0 {
+		if elapsed < threshold {
+			return
+		}
+		level = WarnLevel
+	}
+
+	if !t._wanted(level, fields) {
+		return
+	}
+
+	merged := make(Fields, len(fields)+2)
+	for k, v := range fields {
+		merged[k] = v
+	}
+	merged["op"] = name
+	merged["elapsed_ms"] = float64(elapsed) / float64(time.Millisecond)
+
+	t._logMsg(now, level, name+" took "+elapsed.String(), merged)
+}
@@ -0,0 +1,71 @@
+package easylog
+
+import (
+	"bytes"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+var ErrWriteTimeout = errors.New("easylog: write timed out, dropping entries until the disk catches up")
+
+//set the longest time Write waits for room in the queue under the Block
+//policy. when it expires, the circuit breaker opens: Write stops waiting and
+//drops entries, counted by Dropped, until the next successful flush to disk.
+//this keeps a hung disk (NFS, full device) from freezing every caller.
+//while a timeout is set, Write does not wait for SetMaxInFlight room either.
+//if WriteTimeout == 0, Write waits as long as needed.
+func (t *EasyLog) SetWriteTimeout(WriteTimeout time.Duration) error {
+	if WriteTimeout < 0 {
+		WriteTimeout = 0
+	}
+
+	atomic.StoreInt64(&t.writeTimeout, int64(WriteTimeout))
+	if WriteTimeout == 0 {
+		atomic.StoreInt32(&t.breaker, 0)
+	}
+
+	return nil
+}
+
+func (t *EasyLog) GetWriteTimeout() time.Duration {
+	return time.Duration(atomic.LoadInt64(&t.writeTimeout))
+}
+
+//report whether the circuit breaker is open and entries are being dropped
+func (t *EasyLog) CircuitOpen() bool {
+	return atomic.LoadInt32(&t.breaker) == 1
+}
+
+//queue buf, waiting at most d. it returns false if buf was not queued.
+func (t *EasyLog) _sendTimeout(buf *bytes.Buffer, d time.Duration) bool {
+	if t.CircuitOpen() {
+		return false
+	}
+
+	select {
+	case t.pipe <- buf:
+		return true
+	default:
+	}
+
+	tm := t.clock.NewTimer(d)
+	defer tm.Stop()
+
+	select {
+	case t.pipe <- buf:
+		return true
+	case <-tm.C():
+	}
+
+	if atomic.CompareAndSwapInt32(&t.breaker, 0, 1) {
+		t._reportError(ErrWriteTimeout)
+	}
+
+	return false
+}
+
+//close the breaker once data reached the disk again
+func (t *EasyLog) _resetBreaker() {
+	atomic.StoreInt32(&t.breaker, 0)
+}
@@ -0,0 +1,104 @@
+package easylog
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+//set the largest entry kept in full. longer messages and Write payloads are
+//cut to MaxEntrySize bytes and marked with a "...truncated N bytes" suffix,
+//so a dumped payload can't grow a single entry past the rotation limit.
+//if MaxEntrySize == 0, entries are not limited.
+func (t *EasyLog) SetMaxEntrySize(MaxEntrySize int64) error {
+	if MaxEntrySize < 0 {
+		MaxEntrySize = 0
+	}
+
+	atomic.StoreInt64(&t.maxEntrySize, MaxEntrySize)
+
+	return nil
+}
+
+func (t *EasyLog) GetMaxEntrySize() int64 {
+	return atomic.LoadInt64(&t.maxEntrySize)
+}
+
+//cut msg to the entry size limit, keeping whole utf-8 characters
+func (t *EasyLog) _truncateMsg(msg string) string {
+	max := int(atomic.LoadInt64(&t.maxEntrySize))
+	if max > 0 {
+		//the encoders drop trailing newlines anyway
+		msg = strings.TrimRight(msg, "\n")
+	}
+	if max <= 0 || len(msg) <= max {
+		return msg
+	}
+
+	cut := max
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+
+	return msg[:cut] + "...truncated " + strconv.Itoa(len(msg)-cut) + " bytes"
+}
+
+//cut the message written to buf after offset start to the entry size limit,
+//like _truncateMsg
+func (t *EasyLog) _truncateTail(buf *bytes.Buffer, start int) {
+	max := int(atomic.LoadInt64(&t.maxEntrySize))
+	msg := buf.Bytes()[start:]
+	if max <= 0 || len(msg) <= max {
+		return
+	}
+
+	cut := max
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+
+	n := len(msg) - cut
+	buf.Truncate(start + cut)
+	buf.WriteString("...truncated ")
+	buf.WriteString(strconv.Itoa(n))
+	buf.WriteString(" bytes")
+}
+
+//copy p into buf as one line, see _frameEntry
+func (t *EasyLog) _writeEntry(buf *bytes.Buffer, p []byte) {
+	buf.Write(p)
+	t._frameEntry(buf)
+}
+
+//make the raw entry in buf one record, cut to the entry size limit. a missing
+//separator is added, so entries of concurrent writers never run together.
+//a trailing newline is taken as the separator too.
+func (t *EasyLog) _frameEntry(buf *bytes.Buffer) {
+	t._frameTail(buf, 0)
+}
+
+//frame the raw entry written to buf from start
+func (t *EasyLog) _frameTail(buf *bytes.Buffer, start int) {
+	if buf.Len() == start {
+		return
+	}
+
+	sep := t.GetRecordSeparator()
+	if bytes.HasSuffix(buf.Bytes()[start:], []byte(sep)) {
+		buf.Truncate(buf.Len() - len(sep))
+	} else if buf.Bytes()[buf.Len()-1] == '\n' {
+		buf.Truncate(buf.Len() - 1)
+	}
+
+	if max := int(atomic.LoadInt64(&t.maxEntrySize)); max > 0 && buf.Len()-start > max {
+		n := buf.Len() - start - max
+		buf.Truncate(start + max)
+		buf.WriteString("...truncated ")
+		buf.WriteString(strconv.Itoa(n))
+		buf.WriteString(" bytes")
+	}
+
+	buf.WriteString(sep)
+}